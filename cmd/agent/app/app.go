@@ -17,24 +17,37 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 	"github.com/korovindenis/go-pc-metrics/internal/encrypt"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 // agent functions
+//
+//go:generate mockery --name agentUsecase --exported
 type agentUsecase interface {
 	GetGauge() (entity.GaugeType, error)
 	GetCounter() (entity.CounterType, error)
 
 	UpdateGauge() error
 	UpdateCounter() error
+	UpdateCollectors(ctx context.Context) error
+
+	GetHostInfo() (entity.HostInfo, error)
+	GetAgentID() string
+	GetBuildInfo() entity.BuildInfo
+
+	ApplyConfig(collectors []string, relabel []entity.RelabelRule) error
 }
 
 // logger functions
 type logger interface {
 	Info(msg string, fields ...zapcore.Field)
+	Error(msg string, fields ...zapcore.Field)
 }
 
 // config functions
+//
+//go:generate mockery --name config --exported
 type config interface {
 	GetServerAddressWithScheme() string
 	GetPollInterval() time.Duration
@@ -42,6 +55,13 @@ type config interface {
 	GetKey() string
 	GetRateLimit() int
 	UseCryptoKey() bool
+	GetGroup() string
+
+	GetAgentConfig() entity.AgentConfig
+	ApplyAgentConfig(remote entity.AgentConfig) entity.AgentConfig
+
+	GetMode() string
+	GetListenAddress() string
 }
 
 type resultWorkerMetric struct {
@@ -53,9 +73,18 @@ type resultWorkerMetric struct {
 func Run(ctx context.Context, agentUsecase agentUsecase, log logger, cfg config) error {
 	resultCh := make(chan resultWorkerMetric)
 	defer close(resultCh)
+	// versions of the server config from the /updates/ responses
+	versionCh := make(chan string, 1)
 
 	go updateWorker(ctx, agentUsecase, log, cfg, resultCh)
-	go sendWorker(ctx, agentUsecase, log, cfg, resultCh)
+	if cfg.GetMode() == entity.PullMode {
+		// the server scrapes the agent, nothing is sent
+		go pullServer(ctx, agentUsecase, log, cfg, resultCh)
+	} else {
+		go sendWorker(ctx, agentUsecase, log, cfg, resultCh, versionCh)
+		go inventoryWorker(ctx, agentUsecase, log, cfg)
+		go configWorker(ctx, agentUsecase, log, cfg, versionCh)
+	}
 
 	for {
 		select {
@@ -70,7 +99,8 @@ func Run(ctx context.Context, agentUsecase agentUsecase, log logger, cfg config)
 }
 
 func updateWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cfg config, resultCh chan<- resultWorkerMetric) {
-	updateTicker := time.NewTicker(cfg.GetPollInterval())
+	pollInterval := cfg.GetPollInterval()
+	updateTicker := time.NewTicker(pollInterval)
 	defer updateTicker.Stop()
 
 	for {
@@ -78,6 +108,7 @@ func updateWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cf
 		case <-ctx.Done():
 			return
 		case <-updateTicker.C:
+			pollInterval = resetTicker(updateTicker, pollInterval, cfg.GetPollInterval())
 			log.Info("update metrics")
 			if err := agentUsecase.UpdateGauge(); err != nil {
 				resultCh <- resultWorkerMetric{
@@ -89,6 +120,10 @@ func updateWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cf
 					err: err,
 				}
 			}
+			// collectors errors are not fatal for the agent
+			if err := agentUsecase.UpdateCollectors(ctx); err != nil {
+				log.Error("update collectors", zap.Error(err))
+			}
 			resultCh <- resultWorkerMetric{
 				data: true,
 			}
@@ -96,10 +131,11 @@ func updateWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cf
 	}
 }
 
-func sendWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cfg config, resultCh chan<- resultWorkerMetric) {
-	restClient := resty.New()
+func sendWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cfg config, resultCh chan<- resultWorkerMetric, versionCh chan<- string) {
+	restClient := newRestClient(agentUsecase, cfg)
 	httpServerAddress := cfg.GetServerAddressWithScheme()
-	sendTicker := time.NewTicker(cfg.GetReportInterval())
+	reportInterval := cfg.GetReportInterval()
+	sendTicker := time.NewTicker(reportInterval)
 	secretKey := cfg.GetKey()
 	useCryptoKey := cfg.UseCryptoKey()
 	defer sendTicker.Stop()
@@ -109,6 +145,7 @@ func sendWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cfg
 		case <-ctx.Done():
 			return
 		case <-sendTicker.C:
+			reportInterval = resetTicker(sendTicker, reportInterval, cfg.GetReportInterval())
 			log.Info("send metrics")
 			gaugeVal, err := agentUsecase.GetGauge()
 			if err != nil {
@@ -116,7 +153,7 @@ func sendWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cfg
 					err: err,
 				}
 			}
-			err = sendMetrics(restClient, gaugeVal, log, httpServerAddress, secretKey, useCryptoKey)
+			_, err = sendMetrics(restClient, gaugeVal, log, httpServerAddress, secretKey, useCryptoKey)
 			if err != nil {
 				resultCh <- resultWorkerMetric{
 					err: err,
@@ -128,12 +165,19 @@ func sendWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cfg
 					err: err,
 				}
 			}
-			err = sendMetrics(restClient, counterVal, log, httpServerAddress, secretKey, useCryptoKey)
+			response, err := sendMetrics(restClient, counterVal, log, httpServerAddress, secretKey, useCryptoKey)
 			if err != nil {
 				resultCh <- resultWorkerMetric{
 					err: err,
 				}
 			}
+			// the config worker is busy, the version will come with the next report
+			if response != nil {
+				select {
+				case versionCh <- response.ConfigVersion:
+				default:
+				}
+			}
 			resultCh <- resultWorkerMetric{
 				data: true,
 			}
@@ -141,8 +185,147 @@ func sendWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cfg
 	}
 }
 
-// prepare data
-func sendMetrics(restClient *resty.Client, metricsVal any, log logger, httpServerAddress, secretKey string, useCryptoKey bool) error {
+// sends host facts at startup and when they change
+func inventoryWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cfg config) {
+	restClient := newRestClient(agentUsecase, cfg)
+	httpServerAddress := cfg.GetServerAddressWithScheme()
+	reportInterval := cfg.GetReportInterval()
+	checkTicker := time.NewTicker(reportInterval)
+	secretKey := cfg.GetKey()
+	useCryptoKey := cfg.UseCryptoKey()
+	defer checkTicker.Stop()
+
+	var sent entity.HostInfo
+	send := func() {
+		hostInfo, err := agentUsecase.GetHostInfo()
+		if err != nil {
+			log.Error("get host info", zap.Error(err))
+			return
+		}
+		if hostInfo == sent {
+			return
+		}
+
+		log.Info("send host info")
+		resp, err := postJSON(restClient, log, httpServerAddress+"/inventory/", secretKey, useCryptoKey, hostInfo)
+		if err != nil {
+			log.Error("send host info", zap.Error(err))
+			return
+		}
+		if resp.IsError() {
+			log.Error("send host info: " + resp.Status())
+			return
+		}
+		sent = hostInfo
+	}
+
+	send()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-checkTicker.C:
+			reportInterval = resetTicker(checkTicker, reportInterval, cfg.GetReportInterval())
+			send()
+		}
+	}
+}
+
+// fetches the server config when its version is changed and applies it live,
+// the effective config is reported at startup and after every change
+func configWorker(ctx context.Context, agentUsecase agentUsecase, log logger, cfg config, versionCh <-chan string) {
+	restClient := newRestClient(agentUsecase, cfg)
+	httpServerAddress := cfg.GetServerAddressWithScheme()
+	secretKey := cfg.GetKey()
+	useCryptoKey := cfg.UseCryptoKey()
+
+	report := func(effective entity.AgentConfig) {
+		resp, err := postJSON(restClient, log, httpServerAddress+"/agent-config/", secretKey, useCryptoKey, effective)
+		if err != nil {
+			log.Error("report agent config", zap.Error(err))
+			return
+		}
+		if resp.IsError() {
+			log.Error("report agent config: " + resp.Status())
+		}
+	}
+
+	applied := ""
+	report(cfg.GetAgentConfig())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case version := <-versionCh:
+			if version == applied {
+				continue
+			}
+
+			// without the version the server has no config for the agent
+			var remote entity.AgentConfig
+			if version != "" {
+				resp, err := restClient.R().
+					SetResult(&remote).
+					Get(httpServerAddress + "/agent-config/")
+				if err != nil {
+					log.Error("get agent config", zap.Error(err))
+					continue
+				}
+				if resp.IsError() {
+					log.Error("get agent config: " + resp.Status())
+					continue
+				}
+			}
+
+			effective := cfg.ApplyAgentConfig(remote)
+			if err := agentUsecase.ApplyConfig(effective.Collectors, effective.Relabel); err != nil {
+				log.Error("apply agent config", zap.Error(err))
+			}
+			log.Info("agent config version: " + remote.Version)
+			applied = version
+			report(effective)
+		}
+	}
+}
+
+// resetTicker changes the period of the ticker if the interval is changed
+func resetTicker(ticker *time.Ticker, current, interval time.Duration) time.Duration {
+	if interval > 0 && interval != current {
+		ticker.Reset(interval)
+		return interval
+	}
+	return current
+}
+
+// every request is a heartbeat of the agent in the server registry,
+// the report interval is read per request as the server config can change it live
+func newRestClient(agentUsecase agentUsecase, cfg config) *resty.Client {
+	return resty.New().
+		SetHeader(entity.AgentIDHeader, agentUsecase.GetAgentID()).
+		SetHeader(entity.AgentGroupHeader, cfg.GetGroup()).
+		SetHeader(entity.AgentVersionHeader, agentUsecase.GetBuildInfo().Version).
+		OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+			req.SetHeader(entity.ReportIntervalHeader, strconv.Itoa(int(cfg.GetReportInterval().Seconds())))
+			return nil
+		})
+}
+
+// prepare data, the response is nil if the metrics were not delivered
+func sendMetrics(restClient *resty.Client, metricsVal any, log logger, httpServerAddress, secretKey string, useCryptoKey bool) (*entity.UpdatesResponse, error) {
+	metrics, err := toMetrics(metricsVal)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := httpReq(restClient, log, httpServerAddress, secretKey, useCryptoKey, metrics)
+	if err != nil {
+		return nil, fmt.Errorf("sendMetrics entity.CounterType: %s", err)
+	}
+	return response, nil
+}
+
+// toMetrics converts gauges or counters to the request format
+func toMetrics(metricsVal any) ([]entity.Metrics, error) {
 	var metrics []entity.Metrics
 
 	switch v := metricsVal.(type) {
@@ -153,50 +336,73 @@ func sendMetrics(restClient *resty.Client, metricsVal any, log logger, httpServe
 			*floatValue = value
 			metrics = append(metrics, entity.Metrics{
 				ID:    name,
-				MType: "gauge",
+				MType: entity.GaugeMetric,
 				Value: floatValue,
 			})
 		}
 	case entity.CounterType:
 		for name, value := range metricsVal.(entity.CounterType) {
+			// every metric needs its own copy of the loop variable
+			delta := value
 			metrics = append(metrics, entity.Metrics{
 				ID:    name,
-				MType: "counter",
-				Delta: &value,
+				MType: entity.CounterMetric,
+				Delta: &delta,
 			})
 		}
 	default:
-		return errors.New("sendMetrics(): metricsVal not recognized")
-	}
-
-	if err := httpReq(restClient, log, httpServerAddress, secretKey, useCryptoKey, metrics); err != nil {
-		return fmt.Errorf("sendMetrics entity.CounterType: %s", err)
+		return nil, errors.New("toMetrics(): metricsVal not recognized")
 	}
-	return nil
+	return metrics, nil
 }
 
 // send data
-func httpReq(restyClient *resty.Client, log logger, httpServerAddress, secretKey string, useCryptoKey bool, metrics []entity.Metrics) error {
+func httpReq(restyClient *resty.Client, log logger, httpServerAddress, secretKey string, useCryptoKey bool, metrics []entity.Metrics) (*entity.UpdatesResponse, error) {
+	resp, err := postJSON(restyClient, log, httpServerAddress+"/updates/", secretKey, useCryptoKey, metrics)
+	if resp == nil {
+		return nil, err
+	}
+	if err != nil {
+		log.Info(fmt.Sprintf("error in httpclient: %s", err))
+		return nil, nil
+	}
+
+	if resp.IsError() {
+		log.Info("Status Code:" + resp.Status())
+		log.Info("HTTP Error: " + resp.Status())
+		log.Info("Response Body: " + resp.String())
+		return nil, nil
+	}
 
-	jsonBody, err := json.Marshal(metrics)
+	var response entity.UpdatesResponse
+	if err := json.Unmarshal(resp.Body(), &response); err != nil {
+		log.Info(fmt.Sprintf("error in Unmarshal response: %s", err))
+		return nil, nil
+	}
+	return &response, nil
+}
+
+// postJSON sends compressed and signed (or encrypted) json,
+// the response is nil if the request was not prepared
+func postJSON(restyClient *resty.Client, log logger, url, secretKey string, useCryptoKey bool, payload any) (*resty.Response, error) {
+	jsonBody, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("error in Marshal: %s", err)
+		return nil, fmt.Errorf("error in Marshal: %s", err)
 	}
 
-	log.Info("Send Metrics: " + string(jsonBody))
+	log.Info("Send: " + string(jsonBody))
 
 	var compressedBody bytes.Buffer
 	gz := gzip.NewWriter(&compressedBody)
 	_, err = gz.Write(jsonBody)
 	if err != nil {
-		return fmt.Errorf("error in gz Write: %s", err)
+		return nil, fmt.Errorf("error in gz Write: %s", err)
 	}
 	gz.Close()
 
 	req := restyClient.R().
 		SetHeader("Content-Type", "application/json").
 		SetHeader("Content-Encoding", "gzip").
-		SetHeader("Accept-Encoding", "gzip").
 		SetHeader("Content-Length", strconv.Itoa(compressedBody.Len())).
 		SetBody(compressedBody.Bytes()).
 		EnableTrace()
@@ -215,17 +421,7 @@ func httpReq(restyClient *resty.Client, log logger, httpServerAddress, secretKey
 		}
 	}
 
-	resp, err := req.Execute("POST", httpServerAddress+"/updates/")
-	if err != nil {
-		log.Info(fmt.Sprintf("error in httpclient: %s", err))
-	}
-
-	if resp.IsError() {
-		log.Info("Status Code:" + resp.Status())
-		log.Info("HTTP Error: " + resp.Status())
-		log.Info("Response Body: " + resp.String())
-	}
-	return nil
+	return req.Execute("POST", url)
 }
 
 func computeHMAC(input []byte, key string) (string, error) {
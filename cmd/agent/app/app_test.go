@@ -0,0 +1,48 @@
+// The main application, the client part
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/cmd/agent/app/mocks"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newRestClient(t *testing.T) {
+	// Arrange
+	var mu sync.Mutex
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, r.Header.Get(entity.ReportIntervalHeader))
+	}))
+	defer server.Close()
+
+	agentUsecase := mocks.NewAgentUsecase(t)
+	agentUsecase.On("GetAgentID").Return("agent-1")
+	agentUsecase.On("GetBuildInfo").Return(entity.BuildInfo{Version: "1.0.0"})
+	cfg := mocks.NewConfig(t)
+	cfg.On("GetGroup").Return("")
+	// the server config raises the interval after the first report
+	cfg.On("GetReportInterval").Return(10 * time.Second).Once()
+	cfg.On("GetReportInterval").Return(60 * time.Second).Once()
+	restClient := newRestClient(agentUsecase, cfg)
+
+	// Act
+	_, firstErr := restClient.R().Get(server.URL)
+	_, secondErr := restClient.R().Get(server.URL)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"10", "60"}, got)
+}
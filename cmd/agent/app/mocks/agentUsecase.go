@@ -0,0 +1,225 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AgentUsecase is an autogenerated mock type for the agentUsecase type
+type AgentUsecase struct {
+	mock.Mock
+}
+
+// ApplyConfig provides a mock function with given fields: collectors, relabel
+func (_m *AgentUsecase) ApplyConfig(collectors []string, relabel []entity.RelabelRule) error {
+	ret := _m.Called(collectors, relabel)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyConfig")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string, []entity.RelabelRule) error); ok {
+		r0 = rf(collectors, relabel)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAgentID provides a mock function with given fields:
+func (_m *AgentUsecase) GetAgentID() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAgentID")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetBuildInfo provides a mock function with given fields:
+func (_m *AgentUsecase) GetBuildInfo() entity.BuildInfo {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBuildInfo")
+	}
+
+	var r0 entity.BuildInfo
+	if rf, ok := ret.Get(0).(func() entity.BuildInfo); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(entity.BuildInfo)
+	}
+
+	return r0
+}
+
+// GetCounter provides a mock function with given fields:
+func (_m *AgentUsecase) GetCounter() (entity.CounterType, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCounter")
+	}
+
+	var r0 entity.CounterType
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (entity.CounterType, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() entity.CounterType); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(entity.CounterType)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGauge provides a mock function with given fields:
+func (_m *AgentUsecase) GetGauge() (entity.GaugeType, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGauge")
+	}
+
+	var r0 entity.GaugeType
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (entity.GaugeType, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() entity.GaugeType); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(entity.GaugeType)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHostInfo provides a mock function with given fields:
+func (_m *AgentUsecase) GetHostInfo() (entity.HostInfo, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHostInfo")
+	}
+
+	var r0 entity.HostInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (entity.HostInfo, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() entity.HostInfo); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(entity.HostInfo)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCollectors provides a mock function with given fields: ctx
+func (_m *AgentUsecase) UpdateCollectors(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCollectors")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCounter provides a mock function with given fields:
+func (_m *AgentUsecase) UpdateCounter() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCounter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateGauge provides a mock function with given fields:
+func (_m *AgentUsecase) UpdateGauge() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateGauge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAgentUsecase creates a new instance of AgentUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAgentUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AgentUsecase {
+	mock := &AgentUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,227 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Config is an autogenerated mock type for the config type
+type Config struct {
+	mock.Mock
+}
+
+// ApplyAgentConfig provides a mock function with given fields: remote
+func (_m *Config) ApplyAgentConfig(remote entity.AgentConfig) entity.AgentConfig {
+	ret := _m.Called(remote)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyAgentConfig")
+	}
+
+	var r0 entity.AgentConfig
+	if rf, ok := ret.Get(0).(func(entity.AgentConfig) entity.AgentConfig); ok {
+		r0 = rf(remote)
+	} else {
+		r0 = ret.Get(0).(entity.AgentConfig)
+	}
+
+	return r0
+}
+
+// GetAgentConfig provides a mock function with given fields:
+func (_m *Config) GetAgentConfig() entity.AgentConfig {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAgentConfig")
+	}
+
+	var r0 entity.AgentConfig
+	if rf, ok := ret.Get(0).(func() entity.AgentConfig); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(entity.AgentConfig)
+	}
+
+	return r0
+}
+
+// GetGroup provides a mock function with given fields:
+func (_m *Config) GetGroup() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroup")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetKey provides a mock function with given fields:
+func (_m *Config) GetKey() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetKey")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetListenAddress provides a mock function with given fields:
+func (_m *Config) GetListenAddress() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetListenAddress")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetMode provides a mock function with given fields:
+func (_m *Config) GetMode() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMode")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetPollInterval provides a mock function with given fields:
+func (_m *Config) GetPollInterval() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPollInterval")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// GetRateLimit provides a mock function with given fields:
+func (_m *Config) GetRateLimit() int {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRateLimit")
+	}
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// GetReportInterval provides a mock function with given fields:
+func (_m *Config) GetReportInterval() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReportInterval")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// GetServerAddressWithScheme provides a mock function with given fields:
+func (_m *Config) GetServerAddressWithScheme() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetServerAddressWithScheme")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// UseCryptoKey provides a mock function with given fields:
+func (_m *Config) UseCryptoKey() bool {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseCryptoKey")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// NewConfig creates a new instance of Config. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewConfig(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Config {
+	mock := &Config{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
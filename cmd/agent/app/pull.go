@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/korovindenis/go-pc-metrics/internal/encrypt"
+	"go.uber.org/zap"
+)
+
+// pullServer serves the current metrics for the server scrape manager
+func pullServer(ctx context.Context, agentUsecase agentUsecase, log logger, cfg config, resultCh chan<- resultWorkerMetric) {
+	mux := http.NewServeMux()
+	mux.Handle(entity.ScrapePath, pullHandler(agentUsecase, log, cfg))
+
+	server := &http.Server{
+		Addr:              cfg.GetListenAddress(),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("serve metrics on " + cfg.GetListenAddress())
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		resultCh <- resultWorkerMetric{
+			err: err,
+		}
+	}
+}
+
+// pullHandler returns gauges and counters in the /updates/ format,
+// signed or encrypted like the pushed metrics
+func pullHandler(agentUsecase agentUsecase, log logger, cfg config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, entity.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+			return
+		}
+
+		gauge, err := agentUsecase.GetGauge()
+		if err != nil {
+			log.Error("pull gauge", zap.Error(err))
+			http.Error(w, entity.ErrInternalServerError.Error(), http.StatusInternalServerError)
+			return
+		}
+		counter, err := agentUsecase.GetCounter()
+		if err != nil {
+			log.Error("pull counter", zap.Error(err))
+			http.Error(w, entity.ErrInternalServerError.Error(), http.StatusInternalServerError)
+			return
+		}
+		gaugeMetrics, _ := toMetrics(gauge)
+		counterMetrics, _ := toMetrics(counter)
+
+		body, err := json.Marshal(append(gaugeMetrics, counterMetrics...))
+		if err != nil {
+			log.Error("pull marshal", zap.Error(err))
+			http.Error(w, entity.ErrInternalServerError.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if secretKey := cfg.GetKey(); secretKey != "" {
+			if cfg.UseCryptoKey() {
+				encryptedBody, err := encrypt.Encrypt(secretKey, string(body))
+				if err != nil {
+					log.Error("pull encrypt", zap.Error(err))
+					http.Error(w, entity.ErrInternalServerError.Error(), http.StatusInternalServerError)
+					return
+				}
+				body = []byte(encryptedBody)
+			} else {
+				hashSHA256, _ := computeHMAC(body, secretKey)
+				w.Header().Set("HashSHA256", hashSHA256)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(entity.AgentIDHeader, agentUsecase.GetAgentID())
+		w.Header().Set(entity.AgentGroupHeader, cfg.GetGroup())
+		w.Header().Set(entity.AgentVersionHeader, agentUsecase.GetBuildInfo().Version)
+		w.Write(body)
+	})
+}
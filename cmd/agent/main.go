@@ -9,23 +9,27 @@ import (
 	"time"
 
 	"github.com/korovindenis/go-pc-metrics/cmd/agent/app"
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/dirwatch"
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/expvarscrape"
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/logtail"
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/meminfo"
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/netstat"
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/power"
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/probe"
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/psi"
 	"github.com/korovindenis/go-pc-metrics/internal/agent/config"
+	"github.com/korovindenis/go-pc-metrics/internal/agent/identity"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 	agentUsecase "github.com/korovindenis/go-pc-metrics/internal/domain/usecases/agent"
 	customLogger "github.com/korovindenis/go-pc-metrics/internal/logger"
 	"go.uber.org/zap"
 )
 
 var (
-	buildVersion string
-	buildDate    string
-	buildCommit  string
-)
-
-func init() {
 	buildVersion = "N/A"
-	buildDate = "N/A"
-	buildDate = "N/A"
-}
+	buildDate    = "N/A"
+	buildCommit  = "N/A"
+)
 
 func main() {
 	// for graceful shutdown
@@ -48,11 +52,78 @@ func main() {
 		log.Fatalf("logger: %s\n", err)
 	}
 
+	// init agent ID
+	agentID, err := identity.Load(cfg.GetAgentIDPath())
+	if err != nil {
+		logger.Fatal("init agent id", zap.Error(err))
+	}
+
+	// init collectors
+	var collectors []any
+	if len(cfg.GetLogTailFiles()) > 0 {
+		logTail, err := logtail.New(cfg, logger)
+		if err != nil {
+			logger.Fatal("init logtail collector", zap.Error(err))
+		}
+		defer logTail.Close()
+		collectors = append(collectors, logTail)
+	}
+	if len(cfg.GetProbeTargets()) > 0 {
+		probes, err := probe.New(cfg, logger)
+		if err != nil {
+			logger.Fatal("init probe collector", zap.Error(err))
+		}
+		collectors = append(collectors, probes)
+	}
+	if len(cfg.GetWatchPaths()) > 0 {
+		paths, err := dirwatch.New(cfg, logger)
+		if err != nil {
+			logger.Fatal("init dirwatch collector", zap.Error(err))
+		}
+		collectors = append(collectors, paths)
+	}
+	if len(cfg.GetExpvarTargets()) > 0 {
+		expvars, err := expvarscrape.New(cfg, logger)
+		if err != nil {
+			logger.Fatal("init expvar collector", zap.Error(err))
+		}
+		collectors = append(collectors, expvars)
+	}
+	// system collectors are enabled by the config, it may be changed by the server
+	pressure, err := psi.New(cfg, logger)
+	if err != nil {
+		logger.Fatal("init psi collector", zap.Error(err))
+	}
+	collectors = append(collectors, pressure)
+	sockets, err := netstat.New(cfg, logger)
+	if err != nil {
+		logger.Fatal("init netstat collector", zap.Error(err))
+	}
+	collectors = append(collectors, sockets)
+	memory, err := meminfo.New(cfg, logger)
+	if err != nil {
+		logger.Fatal("init memory collector", zap.Error(err))
+	}
+	collectors = append(collectors, memory)
+	powerSupply, err := power.New(cfg, logger)
+	if err != nil {
+		logger.Fatal("init power collector", zap.Error(err))
+	}
+	collectors = append(collectors, powerSupply)
+
 	// init usecases
-	agentUsecase, err := agentUsecase.New()
+	build := entity.BuildInfo{
+		Version: buildVersion,
+		Date:    buildDate,
+		Commit:  buildCommit,
+	}
+	agentUsecase, err := agentUsecase.New(agentID, build, collectors...)
 	if err != nil {
 		logger.Fatal("init usecases", zap.Error(err))
 	}
+	if err := agentUsecase.ApplyConfig(cfg.GetAgentConfig().Collectors, nil); err != nil {
+		logger.Fatal("init usecases", zap.Error(err))
+	}
 
 	// run agent
 	go func() {
@@ -15,8 +15,21 @@ import (
 type serverHandler interface {
 	ReceptionMetric(c *gin.Context)
 	ReceptionMetrics(c *gin.Context)
+	ReceptionRemoteWrite(c *gin.Context)
+	ReceptionOTLP(c *gin.Context)
+	ReceptionInflux(c *gin.Context)
 	OutputMetric(c *gin.Context)
 	OutputAllMetrics(c *gin.Context)
+	OutputMetricsPrometheus(c *gin.Context)
+	ReceptionHostInfo(c *gin.Context)
+	OutputHosts(c *gin.Context)
+	OutputHostsJSON(c *gin.Context)
+	OutputAgents(c *gin.Context)
+	OutputAgentsJSON(c *gin.Context)
+	OutputTargetsJSON(c *gin.Context)
+	OutputHistory(c *gin.Context)
+	OutputAgentConfig(c *gin.Context)
+	ReceptionAgentConfig(c *gin.Context)
 	Ping(c *gin.Context)
 }
 
@@ -49,7 +62,7 @@ func Run(ctx context.Context, cfg cfg, handler serverHandler, log log) error {
 	router.Use(middleware.Gzip())
 	router.Use(middleware.GzipResponse())
 	if secretKey != "" {
-		const patternSign = `^/updates?/$`
+		const patternSign = `^/(updates?|inventory|agent-config)/$`
 
 		router.Use(middleware.SetSign(secretKey, patternSign))
 		router.Use(middleware.CheckSign(log, secretKey, patternSign))
@@ -57,12 +70,25 @@ func Run(ctx context.Context, cfg cfg, handler serverHandler, log log) error {
 
 	// routes
 	router.GET("/", handler.OutputAllMetrics)
+	router.GET("/metrics", handler.OutputMetricsPrometheus)
 	router.GET("/ping/", handler.Ping)
 	router.GET("/value/:metricType/:metricName", handler.OutputMetric)
 	router.POST("/value/", handler.OutputMetric)
 	router.POST("/update/:metricType/:metricName/:metricVal", handler.ReceptionMetric)
 	router.POST("/update/", handler.ReceptionMetric)
 	router.POST("/updates/", handler.ReceptionMetrics)
+	router.POST("/api/v1/write", handler.ReceptionRemoteWrite)
+	router.POST("/v1/metrics", handler.ReceptionOTLP)
+	router.POST("/write", handler.ReceptionInflux)
+	router.POST("/inventory/", handler.ReceptionHostInfo)
+	router.GET("/hosts/", handler.OutputHosts)
+	router.GET("/api/hosts", handler.OutputHostsJSON)
+	router.GET("/agents/", handler.OutputAgents)
+	router.GET("/api/agents", handler.OutputAgentsJSON)
+	router.GET("/api/targets", handler.OutputTargetsJSON)
+	router.GET("/api/history/:metricType/*metricName", handler.OutputHistory)
+	router.GET("/agent-config/", handler.OutputAgentConfig)
+	router.POST("/agent-config/", handler.ReceptionAgentConfig)
 
 	// add pprof
 	pprof.Register(router)
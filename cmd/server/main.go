@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/korovindenis/go-pc-metrics/cmd/server/app"
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/agentconfig"
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/discovery"
 	"github.com/korovindenis/go-pc-metrics/internal/adapters/storage/disk"
 	"github.com/korovindenis/go-pc-metrics/internal/adapters/storage/memory"
 	database "github.com/korovindenis/go-pc-metrics/internal/adapters/storage/postgresql"
@@ -17,7 +19,9 @@ import (
 	serverusecase "github.com/korovindenis/go-pc-metrics/internal/domain/usecases/server"
 	customLogger "github.com/korovindenis/go-pc-metrics/internal/logger"
 	"github.com/korovindenis/go-pc-metrics/internal/server/config"
+	"github.com/korovindenis/go-pc-metrics/internal/server/graphite"
 	serverhandler "github.com/korovindenis/go-pc-metrics/internal/server/handler"
+	"github.com/korovindenis/go-pc-metrics/internal/server/scrape"
 	"go.uber.org/zap"
 )
 
@@ -72,14 +76,49 @@ func main() {
 		logger.Fatal("init storage", zap.Error(err))
 	}
 
+	// rollups, retention and pool metrics of PostgreSQL tables
+	if databaseStorage, ok := storage.(*database.Storage); ok {
+		defer databaseStorage.Close()
+		go databaseStorage.RunMaintenance(ctx)
+	}
+
+	// init configs of agents
+	var agentConfigs []any
+	if cfg.GetAgentConfigPath() != "" {
+		agentConfigSource, err := agentconfig.New(cfg, logger)
+		if err != nil {
+			logger.Fatal("init agent configs", zap.Error(err))
+		}
+		agentConfigs = append(agentConfigs, agentConfigSource)
+	}
+
 	// init usecases
-	serverUsecase, err := serverusecase.New(storage, cfg)
+	serverUsecase, err := serverusecase.New(storage, cfg, agentConfigs...)
 	if err != nil {
 		logger.Fatal("init usecases", zap.Error(err))
 	}
 
+	// scrape agents in the pull mode
+	var scrapeTargets []any
+	if len(cfg.GetScrapeTargets()) > 0 || cfg.GetScrapeSDDir() != "" {
+		scrapeManager, err := scrape.New(serverUsecase, cfg, logger)
+		if err != nil {
+			logger.Fatal("init scrape manager", zap.Error(err))
+		}
+		discovered := make(chan []entity.ScrapeTarget)
+		if cfg.GetScrapeSDDir() != "" {
+			targetDiscovery, err := discovery.New(cfg, logger)
+			if err != nil {
+				logger.Fatal("init scrape discovery", zap.Error(err))
+			}
+			go targetDiscovery.Run(ctx, discovered)
+		}
+		go scrapeManager.Run(ctx, discovered)
+		scrapeTargets = append(scrapeTargets, scrapeManager)
+	}
+
 	// init handlers
-	serverHandler, err := serverhandler.New(serverUsecase, cfg)
+	serverHandler, err := serverhandler.New(serverUsecase, cfg, scrapeTargets...)
 	if err != nil {
 		logger.Fatal("init handlers", zap.Error(err))
 	}
@@ -92,6 +131,19 @@ func main() {
 		go serverUsecase.SaveAllDataUsecase(ctx, []entity.Metrics{})
 	}
 
+	// listen the Graphite plaintext protocol
+	if cfg.GetGraphiteAddress() != "" {
+		graphiteListener, err := graphite.New(serverUsecase, cfg, logger)
+		if err != nil {
+			logger.Fatal("init graphite listener", zap.Error(err))
+		}
+		go func() {
+			if err := graphiteListener.Run(ctx); err != nil {
+				logger.Error("run graphite listener", zap.Error(err))
+			}
+		}()
+	}
+
 	go func() {
 		// run web server
 		if err := app.Run(ctx, cfg, serverHandler, logger); err != nil {
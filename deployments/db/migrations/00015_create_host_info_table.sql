@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL in Up.
+-- Description: This migration creates the host_info table with facts reported by agents.
+CREATE TABLE host_info (
+    hostname VARCHAR(255) PRIMARY KEY,
+    updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    info JSONB NOT NULL
+);
+
+-- +goose Down
+-- SQL in Down.
+-- Description: This migration drops the host_info table.
+DROP TABLE host_info;
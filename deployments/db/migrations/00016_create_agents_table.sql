@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL in Up.
+-- Description: This migration creates the agents table with the registry of agents.
+CREATE TABLE agents (
+    id VARCHAR(64) PRIMARY KEY,
+    address VARCHAR(255) NOT NULL DEFAULT '',
+    version VARCHAR(255) NOT NULL DEFAULT '',
+    report_interval INTEGER NOT NULL DEFAULT 0,
+    metrics BIGINT NOT NULL DEFAULT 0,
+    first_seen TIMESTAMP NOT NULL,
+    last_seen TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+-- SQL in Down.
+-- Description: This migration drops the agents table.
+DROP TABLE agents;
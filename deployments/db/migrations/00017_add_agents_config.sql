@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL in Up.
+-- Description: This migration adds the group and the effective config of agents.
+ALTER TABLE agents ADD COLUMN agent_group VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE agents ADD COLUMN config JSONB;
+
+-- +goose Down
+-- SQL in Down.
+-- Description: This migration drops the group and the effective config of agents.
+ALTER TABLE agents DROP COLUMN config;
+ALTER TABLE agents DROP COLUMN agent_group;
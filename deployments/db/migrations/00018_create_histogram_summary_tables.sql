@@ -0,0 +1,25 @@
+-- +goose Up
+-- SQL in Up.
+-- Description: This migration creates the histogram and summary tables.
+CREATE TABLE histogram (
+    id SERIAL PRIMARY KEY,
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    name VARCHAR(255) NOT NULL,
+    value JSONB NOT NULL
+);
+
+CREATE TABLE summary (
+    id SERIAL PRIMARY KEY,
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    name VARCHAR(255) NOT NULL,
+    value JSONB NOT NULL
+);
+
+CREATE INDEX histogram_name_id_idx ON histogram (name, id DESC);
+CREATE INDEX summary_name_id_idx ON summary (name, id DESC);
+
+-- +goose Down
+-- SQL in Down.
+-- Description: This migration drops the histogram and summary tables.
+DROP TABLE summary;
+DROP TABLE histogram;
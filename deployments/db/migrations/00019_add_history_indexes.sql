@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQL in Up.
+-- Description: This migration adds indexes for range queries of the history.
+CREATE INDEX gauge_name_created_idx ON gauge (name, created);
+CREATE INDEX counter_name_created_idx ON counter (name, created);
+
+-- +goose Down
+-- SQL in Down.
+-- Description: This migration drops indexes for range queries of the history.
+DROP INDEX counter_name_created_idx;
+DROP INDEX gauge_name_created_idx;
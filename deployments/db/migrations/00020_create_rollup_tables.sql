@@ -0,0 +1,48 @@
+-- +goose Up
+-- SQL in Up.
+-- Description: This migration creates the 1-minute and 1-hour rollup tables of gauges and counters.
+CREATE TABLE gauge_1m (
+    name VARCHAR(255) NOT NULL,
+    bucket TIMESTAMP NOT NULL,
+    avg DOUBLE PRECISION NOT NULL,
+    min DOUBLE PRECISION NOT NULL,
+    max DOUBLE PRECISION NOT NULL,
+    last DOUBLE PRECISION NOT NULL,
+    count BIGINT NOT NULL,
+    PRIMARY KEY (name, bucket)
+);
+
+CREATE TABLE gauge_1h (LIKE gauge_1m INCLUDING ALL);
+
+CREATE TABLE counter_1m (
+    name VARCHAR(255) NOT NULL,
+    bucket TIMESTAMP NOT NULL,
+    min BIGINT NOT NULL,
+    max BIGINT NOT NULL,
+    last BIGINT NOT NULL,
+    count BIGINT NOT NULL,
+    PRIMARY KEY (name, bucket)
+);
+
+CREATE TABLE counter_1h (LIKE counter_1m INCLUDING ALL);
+
+-- the end of the rolled up time of every level
+CREATE TABLE rollup_state (
+    level VARCHAR(16) PRIMARY KEY,
+    rolled_up TIMESTAMP NOT NULL
+);
+
+-- retention keeps the latest row of every name
+CREATE INDEX gauge_name_id_idx ON gauge (name, id);
+CREATE INDEX counter_name_id_idx ON counter (name, id);
+
+-- +goose Down
+-- SQL in Down.
+-- Description: This migration drops the rollup tables of gauges and counters.
+DROP INDEX counter_name_id_idx;
+DROP INDEX gauge_name_id_idx;
+DROP TABLE rollup_state;
+DROP TABLE counter_1h;
+DROP TABLE counter_1m;
+DROP TABLE gauge_1h;
+DROP TABLE gauge_1m;
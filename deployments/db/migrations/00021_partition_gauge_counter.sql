@@ -0,0 +1,98 @@
+-- +goose Up
+-- SQL in Up.
+-- Description: This migration converts the gauge and counter tables to daily range partitions by created,
+-- rows out of the daily partitions go to the default partition. Names keep their type, 00023 widens them.
+ALTER TABLE gauge RENAME TO gauge_unpartitioned;
+ALTER TABLE counter RENAME TO counter_unpartitioned;
+ALTER SEQUENCE gauge_id_seq AS BIGINT;
+ALTER SEQUENCE counter_id_seq AS BIGINT;
+
+CREATE TABLE gauge (
+    id BIGINT NOT NULL DEFAULT nextval('gauge_id_seq'),
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    name CHAR(50) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (id, created)
+) PARTITION BY RANGE (created);
+
+CREATE TABLE counter (
+    id BIGINT NOT NULL DEFAULT nextval('counter_id_seq'),
+    created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    name CHAR(50) NOT NULL,
+    delta BIGINT NOT NULL,
+    PRIMARY KEY (id, created)
+) PARTITION BY RANGE (created);
+
+CREATE TABLE gauge_default PARTITION OF gauge DEFAULT;
+CREATE TABLE counter_default PARTITION OF counter DEFAULT;
+
+-- +goose StatementBegin
+DO $$
+DECLARE
+    metric TEXT;
+    first_day DATE;
+    day DATE;
+BEGIN
+    FOREACH metric IN ARRAY ARRAY['gauge', 'counter'] LOOP
+        EXECUTE format('SELECT min(created)::DATE FROM %I', metric || '_unpartitioned') INTO first_day;
+        FOR day IN
+            SELECT generate_series(LEAST(COALESCE(first_day, CURRENT_DATE), LOCALTIMESTAMP::DATE), LOCALTIMESTAMP::DATE + 3, INTERVAL '1 day')::DATE
+        LOOP
+            EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+                metric || '_p' || to_char(day, 'YYYYMMDD'), metric, day, day + 1);
+        END LOOP;
+    END LOOP;
+END $$;
+-- +goose StatementEnd
+
+INSERT INTO gauge (id, created, name, value)
+SELECT id, COALESCE(created, LOCALTIMESTAMP), name, value FROM gauge_unpartitioned;
+INSERT INTO counter (id, created, name, delta)
+SELECT id, COALESCE(created, LOCALTIMESTAMP), name, delta FROM counter_unpartitioned;
+
+ALTER SEQUENCE gauge_id_seq OWNED BY gauge.id;
+ALTER SEQUENCE counter_id_seq OWNED BY counter.id;
+DROP TABLE gauge_unpartitioned;
+DROP TABLE counter_unpartitioned;
+
+CREATE INDEX gauge_name_id_idx ON gauge (name, id);
+CREATE INDEX counter_name_id_idx ON counter (name, id);
+CREATE INDEX gauge_name_created_idx ON gauge (name, created);
+CREATE INDEX counter_name_created_idx ON counter (name, created);
+
+-- +goose Down
+-- SQL in Down.
+-- Description: This migration converts the gauge and counter tables back to plain tables.
+ALTER TABLE gauge RENAME TO gauge_partitioned;
+ALTER TABLE counter RENAME TO counter_partitioned;
+ALTER SEQUENCE gauge_id_seq AS INTEGER;
+ALTER SEQUENCE counter_id_seq AS INTEGER;
+
+CREATE TABLE gauge (
+    id INTEGER PRIMARY KEY DEFAULT nextval('gauge_id_seq'),
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    name CHAR(50) NOT NULL,
+    value DOUBLE PRECISION NOT NULL
+);
+
+CREATE TABLE counter (
+    id INTEGER PRIMARY KEY DEFAULT nextval('counter_id_seq'),
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    name CHAR(50) NOT NULL,
+    delta BIGINT NOT NULL
+);
+
+INSERT INTO gauge (id, created, name, value)
+SELECT id, created, name, value FROM gauge_partitioned;
+INSERT INTO counter (id, created, name, delta)
+SELECT id, created, name, delta FROM counter_partitioned;
+
+ALTER SEQUENCE gauge_id_seq OWNED BY gauge.id;
+ALTER SEQUENCE counter_id_seq OWNED BY counter.id;
+DROP TABLE gauge_partitioned;
+DROP TABLE counter_partitioned;
+
+CREATE INDEX gauge_name_id_idx ON gauge (name, id);
+CREATE INDEX counter_name_id_idx ON counter (name, id);
+CREATE INDEX gauge_name_created_idx ON gauge (name, created);
+CREATE INDEX counter_name_created_idx ON counter (name, created);
@@ -0,0 +1,27 @@
+-- +goose Up
+-- SQL in Up.
+-- Description: This migration creates the metric_latest table with the current value of every gauge and counter,
+-- the gauge and counter tables keep the history. It is filled with the latest rows of the history.
+CREATE TABLE metric_latest (
+    type VARCHAR(16) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    value DOUBLE PRECISION,
+    delta BIGINT,
+    updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (type, name)
+);
+
+INSERT INTO metric_latest (type, name, value, updated)
+SELECT DISTINCT ON (name) 'gauge', name, value, created
+FROM gauge
+ORDER BY name, id DESC;
+
+INSERT INTO metric_latest (type, name, delta, updated)
+SELECT DISTINCT ON (name) 'counter', name, delta, created
+FROM counter
+ORDER BY name, id DESC;
+
+-- +goose Down
+-- SQL in Down.
+-- Description: This migration drops the metric_latest table.
+DROP TABLE metric_latest;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQL in Up.
+-- Description: This migration widens names of the gauge and counter tables to VARCHAR(255) for series with labels,
+-- the padding of CHAR(50) is removed by the conversion.
+ALTER TABLE gauge ALTER COLUMN name TYPE VARCHAR(255);
+ALTER TABLE counter ALTER COLUMN name TYPE VARCHAR(255);
+
+-- +goose Down
+-- SQL in Down.
+-- Description: This migration narrows names back to CHAR(50), it fails when longer names are stored
+-- instead of truncating them.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM gauge WHERE length(name) > 50) OR EXISTS (SELECT 1 FROM counter WHERE length(name) > 50) THEN
+        RAISE EXCEPTION 'names longer than 50 characters are stored, delete them before the downgrade';
+    END IF;
+END $$;
+-- +goose StatementEnd
+ALTER TABLE gauge ALTER COLUMN name TYPE CHAR(50);
+ALTER TABLE counter ALTER COLUMN name TYPE CHAR(50);
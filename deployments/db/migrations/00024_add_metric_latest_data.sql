@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQL in Up.
+-- Description: This migration adds the current value of histograms and summaries to metric_latest,
+-- so they are merged by one upsert. It is filled with the latest rows of the history.
+ALTER TABLE metric_latest ADD COLUMN data JSONB;
+
+INSERT INTO metric_latest (type, name, data, updated)
+SELECT DISTINCT ON (name) 'histogram', name, value, COALESCE(created, LOCALTIMESTAMP)
+FROM histogram
+ORDER BY name, id DESC;
+
+INSERT INTO metric_latest (type, name, data, updated)
+SELECT DISTINCT ON (name) 'summary', name, value, COALESCE(created, LOCALTIMESTAMP)
+FROM summary
+ORDER BY name, id DESC;
+
+-- +goose Down
+-- SQL in Down.
+-- Description: This migration drops histograms and summaries from metric_latest, the history keeps them.
+DELETE FROM metric_latest WHERE type IN ('histogram', 'summary');
+ALTER TABLE metric_latest DROP COLUMN data;
@@ -0,0 +1,134 @@
+// Configs of agents managed on the server, read from a JSON file
+package agentconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap/zapcore"
+)
+
+// length of the config version in hex
+const versionLength = 12
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetAgentConfigPath() string
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+}
+
+// content of the file,
+// the agent config is default, then the group and then the agent settings
+type file struct {
+	Default entity.AgentConfig            `json:"default"`
+	Groups  map[string]entity.AgentConfig `json:"groups"`
+	Agents  map[string]entity.AgentConfig `json:"agents"`
+}
+
+type Source struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	configs file
+}
+
+func New(config cfg, log log) (*Source, error) {
+	log.Info("Agent configs are " + config.GetAgentConfigPath())
+
+	source := &Source{
+		path: config.GetAgentConfigPath(),
+	}
+	if err := source.reload(); err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
+
+// GetAgentConfig returns the config of the agent with its version,
+// the file is read again when it is changed
+func (s *Source) GetAgentConfig(agentID, group string) (entity.AgentConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return entity.AgentConfig{}, err
+	}
+
+	agentConfig := s.configs.Default
+	if groupConfig, ok := s.configs.Groups[group]; ok && group != "" {
+		agentConfig = merge(agentConfig, groupConfig)
+	}
+	if ownConfig, ok := s.configs.Agents[agentID]; ok {
+		agentConfig = merge(agentConfig, ownConfig)
+	}
+	if isEmpty(agentConfig) {
+		return entity.AgentConfig{}, entity.ErrAgentConfigNotFound
+	}
+
+	agentConfig.Version = ""
+	data, err := json.Marshal(agentConfig)
+	if err != nil {
+		return entity.AgentConfig{}, err
+	}
+	hash := sha256.Sum256(data)
+	agentConfig.Version = hex.EncodeToString(hash[:])[:versionLength]
+
+	return agentConfig, nil
+}
+
+// reload reads the file if its modification time is changed
+func (s *Source) reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	var configs file
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return err
+	}
+
+	s.configs = configs
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// merge overrides the base settings with the set ones
+func merge(base, override entity.AgentConfig) entity.AgentConfig {
+	if override.PollInterval != 0 {
+		base.PollInterval = override.PollInterval
+	}
+	if override.ReportInterval != 0 {
+		base.ReportInterval = override.ReportInterval
+	}
+	if override.Collectors != nil {
+		base.Collectors = override.Collectors
+	}
+	if override.Relabel != nil {
+		base.Relabel = override.Relabel
+	}
+	return base
+}
+
+func isEmpty(agentConfig entity.AgentConfig) bool {
+	return agentConfig.PollInterval == 0 && agentConfig.ReportInterval == 0 &&
+		agentConfig.Collectors == nil && agentConfig.Relabel == nil
+}
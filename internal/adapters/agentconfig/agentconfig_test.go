@@ -0,0 +1,146 @@
+// Configs of agents managed on the server, read from a JSON file
+
+package agentconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/agentconfig/mocks"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const configs = `{
+"default": {"report_interval": 30},
+"groups": {"db": {"collectors": ["psi", "memory"], "poll_interval": 5}},
+"agents": {"c0ffee": {"poll_interval": 1, "relabel": [{"regex": "Alloc", "action": "drop"}]}}
+}`
+
+func writeConfigs(t *testing.T, path, data string, modTime time.Time) {
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newSource(t *testing.T, path string) (*Source, error) {
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetAgentConfigPath").Return(path)
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+
+	return New(cfg, log)
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.json")
+	writeConfigs(t, valid, configs, time.Now())
+	invalid := filepath.Join(dir, "invalid.json")
+	writeConfigs(t, invalid, "{", time.Now())
+
+	tests := []struct {
+		name string
+		path string
+		err  bool
+	}{
+		{
+			name: "positive",
+			path: valid,
+		},
+		{
+			name: "negative json",
+			path: invalid,
+			err:  true,
+		},
+		{
+			name: "negative missing file",
+			path: filepath.Join(dir, "missing.json"),
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			source, err := newSource(t, tt.path)
+
+			// Assert
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, source)
+		})
+	}
+}
+
+func TestSource_GetAgentConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.json")
+	writeConfigs(t, path, configs, time.Now())
+	source, _ := newSource(t, path)
+
+	tests := []struct {
+		name    string
+		agentID string
+		group   string
+		want    entity.AgentConfig
+	}{
+		{
+			name: "default",
+			want: entity.AgentConfig{ReportInterval: 30},
+		},
+		{
+			name:  "group",
+			group: "db",
+			want:  entity.AgentConfig{ReportInterval: 30, PollInterval: 5, Collectors: []string{"psi", "memory"}},
+		},
+		{
+			name:    "agent over group",
+			agentID: "c0ffee",
+			group:   "db",
+			want: entity.AgentConfig{
+				ReportInterval: 30,
+				PollInterval:   1,
+				Collectors:     []string{"psi", "memory"},
+				Relabel:        []entity.RelabelRule{{Regex: "Alloc", Action: entity.RelabelDrop}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			agentConfig, err := source.GetAgentConfig(tt.agentID, tt.group)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Len(t, agentConfig.Version, versionLength)
+			agentConfig.Version = ""
+			assert.Equal(t, tt.want, agentConfig)
+		})
+	}
+}
+
+func TestSource_GetAgentConfig_reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.json")
+	writeConfigs(t, path, `{"groups": {"db": {"poll_interval": 5}}}`, time.Now().Add(-time.Hour))
+	source, _ := newSource(t, path)
+
+	// Act
+	_, errNotFound := source.GetAgentConfig("c0ffee", "web")
+	before, _ := source.GetAgentConfig("c0ffee", "db")
+	writeConfigs(t, path, `{"groups": {"db": {"poll_interval": 10}}}`, time.Now())
+	after, err := source.GetAgentConfig("c0ffee", "db")
+
+	// Assert
+	assert.ErrorIs(t, errNotFound, entity.ErrAgentConfigNotFound)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, after.PollInterval)
+	assert.NotEqual(t, before.Version, after.Version)
+}
@@ -0,0 +1,146 @@
+// Collector of files and directories state
+package dirwatch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const Name = "dirwatch"
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetWatchPaths() []entity.WatchPath
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+}
+
+type Collector struct {
+	log   log
+	paths []entity.WatchPath
+}
+
+// summary of the files found by one watch path
+type pathStat struct {
+	exists bool
+	size   int64
+	files  int64
+	newest time.Time
+	oldest time.Time
+}
+
+func New(config cfg, log log) (*Collector, error) {
+	log.Info("Collector is dirwatch")
+
+	paths := config.GetWatchPaths()
+	for _, p := range paths {
+		if p.Name == "" || p.Path == "" || p.MaxDepth < 0 {
+			return nil, fmt.Errorf("dirwatch %s: %w", p.Path, entity.ErrInvalidCollectorRule)
+		}
+		if _, err := filepath.Match(p.Path, ""); err != nil {
+			return nil, fmt.Errorf("dirwatch %s: %w", p.Path, err)
+		}
+	}
+
+	return &Collector{
+		log:   log,
+		paths: paths,
+	}, nil
+}
+
+func (c *Collector) Name() string {
+	return Name
+}
+
+func (c *Collector) Collect(ctx context.Context) (entity.MetricsType, error) {
+	metrics := entity.MetricsType{
+		Gauge:   make(entity.GaugeType),
+		Counter: make(entity.CounterType),
+	}
+	now := time.Now()
+
+	for _, p := range c.paths {
+		stat, err := c.stat(p)
+		if err != nil {
+			c.log.Info("dirwatch "+p.Path, zap.Error(err))
+		}
+
+		metrics.Gauge[p.Name+"_exists"] = 0
+		if !stat.exists {
+			continue
+		}
+		metrics.Gauge[p.Name+"_exists"] = 1
+		metrics.Gauge[p.Name+"_size_bytes"] = float64(stat.size)
+		metrics.Gauge[p.Name+"_files"] = float64(stat.files)
+		if stat.files > 0 {
+			metrics.Gauge[p.Name+"_newest_age_seconds"] = now.Sub(stat.newest).Seconds()
+			metrics.Gauge[p.Name+"_oldest_age_seconds"] = now.Sub(stat.oldest).Seconds()
+		}
+	}
+
+	return metrics, nil
+}
+
+func (c *Collector) stat(p entity.WatchPath) (pathStat, error) {
+	var stat pathStat
+
+	matches, err := filepath.Glob(p.Path)
+	if err != nil {
+		return stat, err
+	}
+
+	for _, match := range matches {
+		stat.exists = true
+		root := filepath.Clean(match)
+		rootDepth := strings.Count(root, string(filepath.Separator))
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// unreadable entries are skipped
+				if d != nil && d.IsDir() && path != root {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if p.MaxDepth > 0 && path != root && strings.Count(path, string(filepath.Separator))-rootDepth >= p.MaxDepth {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			stat.size += info.Size()
+			stat.files++
+			if stat.newest.IsZero() || info.ModTime().After(stat.newest) {
+				stat.newest = info.ModTime()
+			}
+			if stat.oldest.IsZero() || info.ModTime().Before(stat.oldest) {
+				stat.oldest = info.ModTime()
+			}
+			return nil
+		})
+		if err != nil {
+			return stat, err
+		}
+	}
+
+	return stat, nil
+}
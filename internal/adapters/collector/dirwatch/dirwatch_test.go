@@ -0,0 +1,150 @@
+// Collector of files and directories state
+
+package dirwatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/dirwatch/mocks"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func writeFile(t *testing.T, path string, size int, age time.Duration) {
+	if err := os.MkdirAll(filepath.Dir(path), 0770); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		path entity.WatchPath
+		err  bool
+	}{
+		{
+			name: "positive",
+			path: entity.WatchPath{Name: "backups", Path: "/var/backups/*.tar"},
+		},
+		{
+			name: "negative name",
+			path: entity.WatchPath{Path: "/var/backups"},
+			err:  true,
+		},
+		{
+			name: "negative pattern",
+			path: entity.WatchPath{Name: "backups", Path: "/var/backups/["},
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := mocks.NewCfg(t)
+			cfg.On("GetWatchPaths").Return([]entity.WatchPath{tt.path})
+			log := mocks.NewLog(t)
+			log.On("Info", mock.Anything).Return("")
+
+			// Act
+			collector, err := New(cfg, log)
+
+			// Assert
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, collector)
+		})
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "backups", "db-1.tar"), 100, 2*time.Hour)
+	writeFile(t, filepath.Join(dir, "backups", "db-2.tar"), 200, time.Hour)
+	writeFile(t, filepath.Join(dir, "backups", "notes.txt"), 5, time.Minute)
+	writeFile(t, filepath.Join(dir, "spool", "a"), 10, time.Hour)
+	writeFile(t, filepath.Join(dir, "spool", "deep", "b"), 20, time.Hour)
+	writeFile(t, filepath.Join(dir, "spool", "deep", "deeper", "c"), 30, time.Hour)
+
+	tests := []struct {
+		name   string
+		path   entity.WatchPath
+		exists float64
+		size   float64
+		files  float64
+		newest time.Duration
+		oldest time.Duration
+	}{
+		{
+			name:   "glob",
+			path:   entity.WatchPath{Name: "backups", Path: filepath.Join(dir, "backups", "db-*.tar")},
+			exists: 1,
+			size:   300,
+			files:  2,
+			newest: time.Hour,
+			oldest: 2 * time.Hour,
+		},
+		{
+			name:   "directory",
+			path:   entity.WatchPath{Name: "spool", Path: filepath.Join(dir, "spool")},
+			exists: 1,
+			size:   60,
+			files:  3,
+			newest: time.Hour,
+			oldest: time.Hour,
+		},
+		{
+			name:   "directory with max depth",
+			path:   entity.WatchPath{Name: "spool", Path: filepath.Join(dir, "spool"), MaxDepth: 2},
+			exists: 1,
+			size:   30,
+			files:  2,
+			newest: time.Hour,
+			oldest: time.Hour,
+		},
+		{
+			name: "missing",
+			path: entity.WatchPath{Name: "missing", Path: filepath.Join(dir, "missing")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := mocks.NewCfg(t)
+			cfg.On("GetWatchPaths").Return([]entity.WatchPath{tt.path})
+			log := mocks.NewLog(t)
+			log.On("Info", mock.Anything).Return("")
+			collector, _ := New(cfg, log)
+
+			// Act
+			metrics, err := collector.Collect(context.Background())
+
+			// Assert
+			assert.NoError(t, err)
+			name := tt.path.Name
+			assert.Equal(t, tt.exists, metrics.Gauge[name+"_exists"])
+			if tt.exists == 0 {
+				assert.Len(t, metrics.Gauge, 1)
+				return
+			}
+			assert.Equal(t, tt.size, metrics.Gauge[name+"_size_bytes"])
+			assert.Equal(t, tt.files, metrics.Gauge[name+"_files"])
+			assert.InDelta(t, tt.newest.Seconds(), metrics.Gauge[name+"_newest_age_seconds"], 60)
+			assert.InDelta(t, tt.oldest.Seconds(), metrics.Gauge[name+"_oldest_age_seconds"], 60)
+		})
+	}
+}
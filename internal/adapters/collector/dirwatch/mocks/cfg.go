@@ -0,0 +1,47 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Cfg is an autogenerated mock type for the cfg type
+type Cfg struct {
+	mock.Mock
+}
+
+// GetWatchPaths provides a mock function with given fields:
+func (_m *Cfg) GetWatchPaths() []entity.WatchPath {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWatchPaths")
+	}
+
+	var r0 []entity.WatchPath
+	if rf, ok := ret.Get(0).(func() []entity.WatchPath); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.WatchPath)
+		}
+	}
+
+	return r0
+}
+
+// NewCfg creates a new instance of Cfg. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCfg(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Cfg {
+	mock := &Cfg{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
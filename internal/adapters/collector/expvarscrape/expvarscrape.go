@@ -0,0 +1,149 @@
+// Collector of expvar metrics of local Go services
+package expvarscrape
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const Name = "expvar"
+
+const (
+	defaultTimeout = 5 * time.Second
+	// runtime.MemStats published by the expvar package
+	memstatsKey = "memstats"
+)
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetExpvarTargets() []entity.ExpvarTarget
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+}
+
+type Collector struct {
+	log     log
+	targets []entity.ExpvarTarget
+	client  *http.Client
+}
+
+func New(config cfg, log log) (*Collector, error) {
+	log.Info("Collector is expvar")
+
+	targets := config.GetExpvarTargets()
+	for _, t := range targets {
+		if t.URL == "" || t.Prefix == "" {
+			return nil, fmt.Errorf("expvar %s: %w", t.URL, entity.ErrInvalidCollectorRule)
+		}
+	}
+
+	return &Collector{
+		log:     log,
+		targets: targets,
+		client:  &http.Client{},
+	}, nil
+}
+
+// Collect scrapes all targets in parallel,
+// an unavailable target is reported by <Prefix>.up = 0
+func (c *Collector) Name() string {
+	return Name
+}
+
+func (c *Collector) Collect(ctx context.Context) (entity.MetricsType, error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	metrics := entity.MetricsType{
+		Gauge:   make(entity.GaugeType),
+		Counter: make(entity.CounterType),
+	}
+
+	for _, t := range c.targets {
+		wg.Add(1)
+		go func(t entity.ExpvarTarget) {
+			defer wg.Done()
+
+			gauge := make(entity.GaugeType)
+			vars, err := c.fetch(ctx, t)
+			if err != nil {
+				c.log.Info("expvar "+t.URL, zap.Error(err))
+			}
+			for key, value := range vars {
+				if key == memstatsKey {
+					// same names as the agent runtime gauges
+					flatten(t.Prefix, value, gauge)
+					continue
+				}
+				flatten(t.Prefix+"."+key, value, gauge)
+			}
+			gauge[t.Prefix+".up"] = 0
+			if err == nil {
+				gauge[t.Prefix+".up"] = 1
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for name, value := range gauge {
+				metrics.Gauge[name] = value
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	return metrics, nil
+}
+
+func (c *Collector) fetch(ctx context.Context, t entity.ExpvarTarget) (map[string]any, error) {
+	timeout := time.Duration(t.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.URL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %s", resp.Status)
+	}
+
+	var vars map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// flatten adds numeric fields of nested objects as dotted names,
+// arrays, strings and bools are skipped
+func flatten(name string, value any, gauge entity.GaugeType) {
+	switch v := value.(type) {
+	case float64:
+		gauge[name] = v
+	case map[string]any:
+		for key, nested := range v {
+			flatten(name+"."+key, nested, gauge)
+		}
+	}
+}
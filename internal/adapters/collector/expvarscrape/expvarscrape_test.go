@@ -0,0 +1,114 @@
+// Collector of expvar metrics of local Go services
+
+package expvarscrape
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/expvarscrape/mocks"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const debugVars = `{
+"cmdline": ["/usr/bin/billing", "-v"],
+"memstats": {"HeapAlloc": 1024, "NumGC": 3, "PauseNs": [1, 2, 3], "EnableGC": true},
+"requests": 42,
+"queue": {"depth": 5, "worker": {"busy": 2}, "name": "main"}
+}`
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		target entity.ExpvarTarget
+		err    bool
+	}{
+		{
+			name:   "positive",
+			target: entity.ExpvarTarget{URL: "http://localhost:6060/debug/vars", Prefix: "billing"},
+		},
+		{
+			name:   "negative prefix",
+			target: entity.ExpvarTarget{URL: "http://localhost:6060/debug/vars"},
+			err:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := mocks.NewCfg(t)
+			cfg.On("GetExpvarTargets").Return([]entity.ExpvarTarget{tt.target})
+			log := mocks.NewLog(t)
+			log.On("Info", mock.Anything).Return("")
+
+			// Act
+			collector, err := New(cfg, log)
+
+			// Assert
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, collector)
+		})
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/vars", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(debugVars))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		url   string
+		gauge entity.GaugeType
+	}{
+		{
+			name: "positive",
+			url:  server.URL + "/debug/vars",
+			gauge: entity.GaugeType{
+				"billing.up":                1,
+				"billing.HeapAlloc":         1024,
+				"billing.NumGC":             3,
+				"billing.requests":          42,
+				"billing.queue.depth":       5,
+				"billing.queue.worker.busy": 2,
+			},
+		},
+		{
+			name: "unavailable",
+			url:  server.URL + "/missing",
+			gauge: entity.GaugeType{
+				"billing.up": 0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := mocks.NewCfg(t)
+			cfg.On("GetExpvarTargets").Return([]entity.ExpvarTarget{{URL: tt.url, Prefix: "billing"}})
+			log := mocks.NewLog(t)
+			log.On("Info", mock.Anything).Return("")
+			log.On("Info", mock.Anything, mock.Anything).Return("").Maybe()
+			collector, _ := New(cfg, log)
+
+			// Act
+			metrics, err := collector.Collect(context.Background())
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.gauge, metrics.Gauge)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package logtail
+
+import (
+	"os"
+	"syscall"
+)
+
+// inode identifies the file behind the path, it changes on rotation
+func inodeOf(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return 0
+}
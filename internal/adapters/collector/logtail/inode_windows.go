@@ -0,0 +1,8 @@
+package logtail
+
+import "os"
+
+// rotation is detected by truncation only
+func inodeOf(info os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,379 @@
+// Collector of log file matches
+package logtail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const Name = "logtail"
+
+const (
+	// max bytes read from one file per collect
+	maxReadSize = 4 << 20 // 4 MB
+	// an unfinished line longer than this is dropped
+	maxLineSize = 64 << 10 // 64 KB
+)
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetLogTailFiles() []entity.LogTailFile
+	GetLogTailStatePath() string
+	GetReportInterval() time.Duration
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+}
+
+type rule struct {
+	name   string
+	re     *regexp.Regexp
+	gauges bool
+}
+
+type tailedFile struct {
+	path    string
+	rules   []rule
+	file    *os.File
+	inode   uint64
+	offset  int64
+	partial []byte
+}
+
+// position of the file, saved between agent restarts
+type position struct {
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+}
+
+type Collector struct {
+	mu          sync.Mutex
+	log         log
+	files       []*tailedFile
+	statePath   string
+	window      time.Duration
+	windowStart time.Time
+	last        entity.GaugeType
+	max         entity.GaugeType
+}
+
+func New(config cfg, log log) (*Collector, error) {
+	log.Info("Collector is logtail")
+
+	collector := &Collector{
+		log:         log,
+		statePath:   config.GetLogTailStatePath(),
+		window:      config.GetReportInterval(),
+		windowStart: time.Now(),
+		last:        make(entity.GaugeType),
+		max:         make(entity.GaugeType),
+	}
+
+	state, err := collector.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range config.GetLogTailFiles() {
+		file := &tailedFile{
+			path: f.Path,
+		}
+		for _, r := range f.Rules {
+			if r.Name == "" {
+				return nil, fmt.Errorf("logtail %s: %w", f.Path, entity.ErrInvalidCollectorRule)
+			}
+			re, err := regexp.Compile(r.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("logtail %s %s: %w", f.Path, r.Name, err)
+			}
+			file.rules = append(file.rules, rule{
+				name:   r.Name,
+				re:     re,
+				gauges: r.Gauges,
+			})
+		}
+
+		pos, known := state[f.Path]
+		if err := file.open(pos, known); err != nil {
+			return nil, err
+		}
+		collector.files = append(collector.files, file)
+	}
+
+	return collector, nil
+}
+
+// Collect reads the lines appended since the previous call
+func (c *Collector) Name() string {
+	return Name
+}
+
+func (c *Collector) Collect(ctx context.Context) (entity.MetricsType, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics := entity.MetricsType{
+		Gauge:   make(entity.GaugeType),
+		Counter: make(entity.CounterType),
+	}
+
+	// max is kept for the report window only
+	if time.Since(c.windowStart) >= c.window {
+		c.max = make(entity.GaugeType)
+		c.windowStart = time.Now()
+	}
+
+	for _, f := range c.files {
+		for _, r := range f.rules {
+			metrics.Counter[r.name] += 0
+		}
+
+		lines, err := f.readLines()
+		if err != nil {
+			c.log.Info("logtail read "+f.path, zap.Error(err))
+			continue
+		}
+		for _, line := range lines {
+			c.match(f, line, metrics)
+		}
+	}
+
+	for name, value := range c.last {
+		metrics.Gauge[name+"_last"] = value
+	}
+	for name, value := range c.max {
+		metrics.Gauge[name+"_max"] = value
+	}
+
+	return metrics, c.saveState()
+}
+
+// Close releases the opened files
+func (c *Collector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for _, f := range c.files {
+		if f.file != nil {
+			errs = append(errs, f.file.Close())
+			f.file = nil
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Collector) match(f *tailedFile, line string, metrics entity.MetricsType) {
+	for _, r := range f.rules {
+		groups := r.re.FindStringSubmatch(line)
+		if groups == nil {
+			continue
+		}
+		metrics.Counter[r.name]++
+
+		if !r.gauges {
+			continue
+		}
+		for i, group := range r.re.SubexpNames() {
+			if group == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(groups[i], 64)
+			if err != nil {
+				continue
+			}
+			name := r.name + "_" + group
+			c.last[name] = value
+			if current, ok := c.max[name]; !ok || value > current {
+				c.max[name] = value
+			}
+		}
+	}
+}
+
+func (c *Collector) loadState() (map[string]position, error) {
+	state := make(map[string]position)
+
+	data, err := os.ReadFile(c.statePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return state, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		c.log.Info("logtail state is broken, starting from the end of files", zap.Error(err))
+		return make(map[string]position), nil
+	}
+
+	return state, nil
+}
+
+func (c *Collector) saveState() error {
+	state := make(map[string]position, len(c.files))
+	for _, f := range c.files {
+		if f.file == nil {
+			continue
+		}
+		// the unfinished line will be read again after restart
+		state[f.path] = position{
+			Inode:  f.inode,
+			Offset: f.offset - int64(len(f.partial)),
+		}
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.statePath), 0770); err != nil {
+		return err
+	}
+
+	// write and rename, so the state is never half written
+	tmpPath := c.statePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, c.statePath)
+}
+
+// open the file at startup: continue from the saved position,
+// or from the end if the file was never seen before
+func (f *tailedFile) open(pos position, known bool) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// will be read from the beginning when it appears
+			return nil
+		}
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.inode = inodeOf(info)
+	switch {
+	case !known:
+		f.offset = info.Size()
+	case pos.Inode == f.inode && pos.Offset <= info.Size():
+		f.offset = pos.Offset
+	default:
+		// rotated or truncated while the agent was stopped
+		f.offset = 0
+	}
+
+	return nil
+}
+
+// readLines returns complete lines appended since the previous call,
+// following rotation (new file under the same path) and truncation
+func (f *tailedFile) readLines() ([]string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			if f.file == nil {
+				return nil, nil
+			}
+			// rotated, the new file is not created yet
+			return f.read()
+		}
+		return nil, err
+	}
+	if f.file != nil && inodeOf(info) == f.inode {
+		return f.read()
+	}
+
+	// rotated: finish the previous file before switching
+	var lines []string
+	if f.file != nil {
+		lines, err = f.read()
+		if err != nil {
+			return nil, err
+		}
+		if len(f.partial) > 0 {
+			lines = append(lines, string(f.partial))
+		}
+		f.file.Close()
+		f.file = nil
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return lines, err
+	}
+	f.file = file
+	f.inode = inodeOf(info)
+	f.offset = 0
+	f.partial = nil
+
+	newLines, err := f.read()
+	return append(lines, newLines...), err
+}
+
+func (f *tailedFile) read() ([]string, error) {
+	info, err := f.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < f.offset {
+		// truncated
+		f.offset = 0
+		f.partial = nil
+	}
+
+	size := info.Size() - f.offset
+	if size == 0 {
+		return nil, nil
+	}
+	if size > maxReadSize {
+		size = maxReadSize
+	}
+
+	buf := make([]byte, size)
+	n, err := f.file.ReadAt(buf, f.offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	f.offset += int64(n)
+
+	data := append(f.partial, buf[:n]...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		f.partial = data
+		if len(f.partial) > maxLineSize {
+			f.partial = nil
+		}
+		return nil, nil
+	}
+	f.partial = append([]byte(nil), data[end+1:]...)
+
+	lines := strings.Split(string(data[:end]), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,217 @@
+// Collector of log file matches
+
+package logtail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/logtail/mocks"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestCollector(t *testing.T, dir string, files []entity.LogTailFile) *Collector {
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetLogTailFiles").Return(files)
+	cfg.On("GetLogTailStatePath").Return(filepath.Join(dir, "state.json"))
+	cfg.On("GetReportInterval").Return(time.Hour)
+
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything, mock.Anything).Return().Maybe()
+	log.On("Info", mock.Anything).Return().Maybe()
+
+	collector, err := New(cfg, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { collector.Close() })
+
+	return collector
+}
+
+func appendLines(t *testing.T, path string, lines string) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(lines); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []entity.LogTailRule
+		err   bool
+	}{
+		{
+			name:  "positive",
+			rules: []entity.LogTailRule{{Name: "Errors", Regex: "level=error"}},
+		},
+		{
+			name:  "negative empty name",
+			rules: []entity.LogTailRule{{Regex: "level=error"}},
+			err:   true,
+		},
+		{
+			name:  "negative regex",
+			rules: []entity.LogTailRule{{Name: "Errors", Regex: "level=("}},
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			cfg := mocks.NewCfg(t)
+			cfg.On("GetLogTailFiles").Return([]entity.LogTailFile{{Path: filepath.Join(dir, "app.log"), Rules: tt.rules}})
+			cfg.On("GetLogTailStatePath").Return(filepath.Join(dir, "state.json"))
+			cfg.On("GetReportInterval").Return(time.Hour)
+			log := mocks.NewLog(t)
+			log.On("Info", mock.Anything).Return("")
+
+			// Act
+			collector, err := New(cfg, log)
+
+			// Assert
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, collector)
+		})
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendLines(t, path, "level=error old line\n")
+
+	collector := newTestCollector(t, dir, []entity.LogTailFile{
+		{
+			Path: path,
+			Rules: []entity.LogTailRule{
+				{Name: "Errors", Regex: "level=error"},
+				{Name: "Requests", Regex: `latency_ms=(?P<latency>\d+)`, Gauges: true},
+			},
+		},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		prepare func()
+		counter entity.CounterType
+		gauge   entity.GaugeType
+	}{
+		{
+			name:    "existing lines are skipped",
+			prepare: func() {},
+			counter: entity.CounterType{"Errors": 0, "Requests": 0},
+			gauge:   entity.GaugeType{},
+		},
+		{
+			name: "new lines",
+			prepare: func() {
+				appendLines(t, path, "level=error a\nlevel=info latency_ms=10\nlevel=error latency_ms=30\nlevel=info latency_ms=20\n")
+			},
+			counter: entity.CounterType{"Errors": 2, "Requests": 3},
+			gauge:   entity.GaugeType{"Requests_latency_last": 20, "Requests_latency_max": 30},
+		},
+		{
+			name: "unfinished line waits for newline",
+			prepare: func() {
+				appendLines(t, path, "level=err")
+			},
+			counter: entity.CounterType{"Errors": 0, "Requests": 0},
+			gauge:   entity.GaugeType{"Requests_latency_last": 20, "Requests_latency_max": 30},
+		},
+		{
+			name: "finished line",
+			prepare: func() {
+				appendLines(t, path, "or b\n")
+			},
+			counter: entity.CounterType{"Errors": 1, "Requests": 0},
+			gauge:   entity.GaugeType{"Requests_latency_last": 20, "Requests_latency_max": 30},
+		},
+		{
+			name: "truncation",
+			prepare: func() {
+				if err := os.Truncate(path, 0); err != nil {
+					t.Fatal(err)
+				}
+				appendLines(t, path, "level=error c\n")
+			},
+			counter: entity.CounterType{"Errors": 1, "Requests": 0},
+			gauge:   entity.GaugeType{"Requests_latency_last": 20, "Requests_latency_max": 30},
+		},
+		{
+			name: "rotation",
+			prepare: func() {
+				appendLines(t, path, "level=error d\n")
+				if err := os.Rename(path, path+".1"); err != nil {
+					t.Fatal(err)
+				}
+				appendLines(t, path, "level=error e\nlevel=error f\n")
+			},
+			counter: entity.CounterType{"Errors": 3, "Requests": 0},
+			gauge:   entity.GaugeType{"Requests_latency_last": 20, "Requests_latency_max": 30},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.prepare()
+
+			// Act
+			metrics, err := collector.Collect(ctx)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.counter, metrics.Counter)
+			assert.Equal(t, tt.gauge, metrics.Gauge)
+		})
+	}
+}
+
+func TestCollector_State(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	files := []entity.LogTailFile{
+		{
+			Path:  path,
+			Rules: []entity.LogTailRule{{Name: "Errors", Regex: "level=error"}},
+		},
+	}
+	ctx := context.Background()
+	appendLines(t, path, "level=error a\n")
+
+	// Arrange
+	first := newTestCollector(t, dir, files)
+	appendLines(t, path, "level=error b\n")
+	metrics, err := first.Collect(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), metrics.Counter["Errors"])
+	first.Close()
+
+	// lines written while the agent is stopped
+	appendLines(t, path, "level=error c\nlevel=error d\n")
+
+	// Act
+	second := newTestCollector(t, dir, files)
+	metrics, err = second.Collect(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), metrics.Counter["Errors"])
+}
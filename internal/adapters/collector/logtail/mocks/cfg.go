@@ -0,0 +1,86 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Cfg is an autogenerated mock type for the cfg type
+type Cfg struct {
+	mock.Mock
+}
+
+// GetLogTailFiles provides a mock function with given fields:
+func (_m *Cfg) GetLogTailFiles() []entity.LogTailFile {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLogTailFiles")
+	}
+
+	var r0 []entity.LogTailFile
+	if rf, ok := ret.Get(0).(func() []entity.LogTailFile); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.LogTailFile)
+		}
+	}
+
+	return r0
+}
+
+// GetLogTailStatePath provides a mock function with given fields:
+func (_m *Cfg) GetLogTailStatePath() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLogTailStatePath")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetReportInterval provides a mock function with given fields:
+func (_m *Cfg) GetReportInterval() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReportInterval")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// NewCfg creates a new instance of Cfg. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCfg(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Cfg {
+	mock := &Cfg{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,153 @@
+// Collector of memory and swap details
+package meminfo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/shirou/gopsutil/v3/mem"
+	"go.uber.org/zap/zapcore"
+)
+
+const Name = "memory"
+
+// counters from /proc/vmstat
+var vmstatCounters = map[string]string{
+	"pgmajfault": "MajorPageFaults",
+	"oom_kill":   "OOMKills",
+}
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetProcfsRoot() string
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+}
+
+type Collector struct {
+	mu   sync.Mutex
+	root string
+	// replaced in tests
+	virtualMemory func() (*mem.VirtualMemoryStat, error)
+	swapMemory    func() (*mem.SwapMemoryStat, error)
+	// values of the previous collect, for rates and increments
+	prevTime time.Time
+	prevSwap *mem.SwapMemoryStat
+	totals   map[string]int64
+}
+
+func New(config cfg, log log) (*Collector, error) {
+	log.Info("Collector is memory")
+
+	collector := &Collector{
+		root:          config.GetProcfsRoot(),
+		virtualMemory: mem.VirtualMemory,
+		swapMemory:    mem.SwapMemory,
+		totals:        make(map[string]int64),
+	}
+
+	return collector, nil
+}
+
+func (c *Collector) Name() string {
+	return Name
+}
+
+func (c *Collector) Collect(ctx context.Context) (entity.MetricsType, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics := entity.MetricsType{
+		Gauge:   make(entity.GaugeType),
+		Counter: make(entity.CounterType),
+	}
+
+	memInfo, err := c.virtualMemory()
+	if err != nil {
+		return metrics, err
+	}
+	metrics.Gauge["AvailableMemory"] = float64(memInfo.Available)
+	metrics.Gauge["BuffersMemory"] = float64(memInfo.Buffers)
+	metrics.Gauge["CachedMemory"] = float64(memInfo.Cached)
+	metrics.Gauge["DirtyMemory"] = float64(memInfo.Dirty)
+	metrics.Gauge["WritebackMemory"] = float64(memInfo.WriteBack)
+	metrics.Gauge["SlabMemory"] = float64(memInfo.Slab)
+	metrics.Gauge["SharedMemory"] = float64(memInfo.Shared)
+	metrics.Gauge["HugePagesTotal"] = float64(memInfo.HugePagesTotal)
+	metrics.Gauge["HugePagesFree"] = float64(memInfo.HugePagesFree)
+	metrics.Gauge["HugePageSize"] = float64(memInfo.HugePageSize)
+
+	swapInfo, err := c.swapMemory()
+	if err != nil {
+		return metrics, err
+	}
+	now := time.Now()
+	metrics.Gauge["SwapTotal"] = float64(swapInfo.Total)
+	metrics.Gauge["SwapUsed"] = float64(swapInfo.Used)
+	metrics.Gauge["SwapFree"] = float64(swapInfo.Free)
+	// bytes per second since the previous collect
+	metrics.Gauge["SwapInRate"] = 0
+	metrics.Gauge["SwapOutRate"] = 0
+	if c.prevSwap != nil {
+		elapsed := now.Sub(c.prevTime).Seconds()
+		if elapsed > 0 && swapInfo.Sin >= c.prevSwap.Sin && swapInfo.Sout >= c.prevSwap.Sout {
+			metrics.Gauge["SwapInRate"] = float64(swapInfo.Sin-c.prevSwap.Sin) / elapsed
+			metrics.Gauge["SwapOutRate"] = float64(swapInfo.Sout-c.prevSwap.Sout) / elapsed
+		}
+	}
+	c.prevSwap = swapInfo
+	c.prevTime = now
+
+	if err := c.readVmstat(metrics); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return metrics, err
+	}
+
+	return metrics, nil
+}
+
+// readVmstat parses lines like "pgmajfault 1234"
+func (c *Collector) readVmstat(metrics entity.MetricsType) error {
+	f, err := os.Open(filepath.Join(c.root, "vmstat"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		name, ok := vmstatCounters[key]
+		if !ok {
+			continue
+		}
+		total, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return fmt.Errorf("vmstat %s: %w", key, err)
+		}
+
+		if prev, ok := c.totals[name]; ok && total >= prev {
+			metrics.Counter[name] = total - prev
+		} else {
+			metrics.Counter[name] = 0
+		}
+		c.totals[name] = total
+	}
+
+	return scanner.Err()
+}
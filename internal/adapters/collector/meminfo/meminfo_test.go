@@ -0,0 +1,101 @@
+// Collector of memory and swap details
+
+package meminfo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/meminfo/mocks"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCollector_Collect(t *testing.T) {
+	root := t.TempDir()
+	writeVmstat := func(content string) {
+		if err := os.WriteFile(filepath.Join(root, "vmstat"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetProcfsRoot").Return(root)
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	collector, _ := New(cfg, log)
+
+	swap := &mem.SwapMemoryStat{Total: 1000, Used: 100, Free: 900}
+	collector.virtualMemory = func() (*mem.VirtualMemoryStat, error) {
+		return &mem.VirtualMemoryStat{Available: 500, Cached: 200, Dirty: 10, HugePagesTotal: 4}, nil
+	}
+	collector.swapMemory = func() (*mem.SwapMemoryStat, error) {
+		return swap, nil
+	}
+
+	tests := []struct {
+		name         string
+		vmstat       string
+		swapIn       uint64
+		majorFaults  int64
+		oomKills     int64
+		swapInActive bool
+	}{
+		{
+			name:   "first collect",
+			vmstat: "pgfault 100\npgmajfault 40\noom_kill 1\n",
+		},
+		{
+			name:         "increments",
+			vmstat:       "pgfault 150\npgmajfault 47\noom_kill 3\n",
+			swapIn:       4096,
+			majorFaults:  7,
+			oomKills:     2,
+			swapInActive: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			writeVmstat(tt.vmstat)
+			swap = &mem.SwapMemoryStat{Total: 1000, Used: 100, Free: 900, Sin: tt.swapIn}
+
+			// Act
+			metrics, err := collector.Collect(context.Background())
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, float64(500), metrics.Gauge["AvailableMemory"])
+			assert.Equal(t, float64(200), metrics.Gauge["CachedMemory"])
+			assert.Equal(t, float64(4), metrics.Gauge["HugePagesTotal"])
+			assert.Equal(t, float64(100), metrics.Gauge["SwapUsed"])
+			assert.Equal(t, tt.swapInActive, metrics.Gauge["SwapInRate"] > 0)
+			assert.Equal(t, float64(0), metrics.Gauge["SwapOutRate"])
+			assert.Equal(t, tt.majorFaults, metrics.Counter["MajorPageFaults"])
+			assert.Equal(t, tt.oomKills, metrics.Counter["OOMKills"])
+			assert.NotContains(t, metrics.Counter, "pgfault")
+		})
+	}
+}
+
+func TestCollector_CollectError(t *testing.T) {
+	// Arrange
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetProcfsRoot").Return(t.TempDir())
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	collector, _ := New(cfg, log)
+	collector.virtualMemory = func() (*mem.VirtualMemoryStat, error) {
+		return nil, errors.New("err")
+	}
+
+	// Act
+	_, err := collector.Collect(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,217 @@
+// Collector of sockets and network protocols statistics
+package netstat
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const Name = "netstat"
+
+// states of /proc/net/tcp in hex
+var tcpStates = map[string]string{
+	"01": "Established",
+	"02": "SynSent",
+	"03": "SynRecv",
+	"04": "FinWait1",
+	"05": "FinWait2",
+	"06": "TimeWait",
+	"07": "Close",
+	"08": "CloseWait",
+	"09": "LastAck",
+	"0A": "Listen",
+	"0B": "Closing",
+}
+
+// protocol counters reported from /proc/net/snmp and /proc/net/netstat
+var protocolCounters = map[string][]string{
+	"Tcp":    {"RetransSegs", "OutRsts", "EstabResets", "AttemptFails"},
+	"TcpExt": {"ListenOverflows", "ListenDrops", "TCPAbortOnData", "TCPAbortOnClose"},
+	"Udp":    {"InErrors", "RcvbufErrors", "SndbufErrors"},
+}
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetProcfsRoot() string
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+}
+
+type Collector struct {
+	mu       sync.Mutex
+	root     string
+	disabled bool
+	// totals of the previous collect, counters are sent as increments
+	totals map[string]int64
+}
+
+func New(config cfg, log log) (*Collector, error) {
+	log.Info("Collector is netstat")
+
+	collector := &Collector{
+		root:   config.GetProcfsRoot(),
+		totals: make(map[string]int64),
+	}
+
+	// the first collect only remembers totals
+	if _, err := collector.Collect(context.Background()); err != nil {
+		log.Info("procfs is not readable, collector netstat is disabled", zap.Error(err))
+		collector.disabled = true
+	}
+
+	return collector, nil
+}
+
+func (c *Collector) Name() string {
+	return Name
+}
+
+func (c *Collector) Collect(ctx context.Context) (entity.MetricsType, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics := entity.MetricsType{
+		Gauge:   make(entity.GaugeType),
+		Counter: make(entity.CounterType),
+	}
+	if c.disabled {
+		return metrics, nil
+	}
+
+	for _, state := range tcpStates {
+		metrics.Gauge["TCPConn"+state] = 0
+	}
+	for _, file := range []string{"net/tcp", "net/tcp6"} {
+		if err := c.readTCP(file, metrics); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return metrics, err
+		}
+	}
+
+	for _, file := range []string{"net/snmp", "net/netstat"} {
+		if err := c.readProtocols(file, metrics); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return metrics, err
+		}
+	}
+
+	if err := c.readFileNr(metrics); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return metrics, err
+	}
+
+	return metrics, nil
+}
+
+// readTCP counts connections by the "st" column
+func (c *Collector) readTCP(file string, metrics entity.MetricsType) error {
+	f, err := os.Open(filepath.Join(c.root, file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// skip header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		if state, ok := tcpStates[strings.ToUpper(fields[3])]; ok {
+			metrics.Gauge["TCPConn"+state]++
+		}
+	}
+
+	return scanner.Err()
+}
+
+// readProtocols parses pairs of lines
+// Tcp: RtoAlgorithm RtoMin ...
+// Tcp: 1 200 ...
+func (c *Collector) readProtocols(file string, metrics entity.MetricsType) error {
+	f, err := os.Open(filepath.Join(c.root, file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var header []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if header == nil || header[0] != fields[0] {
+			header = fields
+			continue
+		}
+
+		protocol := strings.TrimSuffix(fields[0], ":")
+		values := make(map[string]string, len(fields))
+		for i := 1; i < len(fields) && i < len(header); i++ {
+			values[header[i]] = fields[i]
+		}
+		header = nil
+
+		for _, name := range protocolCounters[protocol] {
+			value, ok := values[name]
+			if !ok {
+				continue
+			}
+			total, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", file, name, err)
+			}
+			c.increment(protocol+name, total, metrics)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// readFileNr parses "allocated unused max"
+func (c *Collector) readFileNr(metrics entity.MetricsType) error {
+	data, err := os.ReadFile(filepath.Join(c.root, "sys/fs/file-nr"))
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) != 3 {
+		return fmt.Errorf("file-nr: %w", entity.ErrInputVarIsWrongType)
+	}
+	names := []string{"FileDescriptorsAllocated", "FileDescriptorsUnused", "FileDescriptorsMax"}
+	for i, name := range names {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return fmt.Errorf("file-nr: %w", err)
+		}
+		metrics.Gauge[name] = value
+	}
+
+	return nil
+}
+
+func (c *Collector) increment(name string, total int64, metrics entity.MetricsType) {
+	if prev, ok := c.totals[name]; ok && total >= prev {
+		metrics.Counter[name] = total - prev
+	} else {
+		metrics.Counter[name] = 0
+	}
+	c.totals[name] = total
+}
@@ -0,0 +1,126 @@
+// Collector of sockets and network protocols statistics
+
+package netstat
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/netstat/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	tcpHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	tcpListen = "   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 100 1\n"
+	tcpEstab  = "   1: 0100007F:1F90 0100007F:C350 01 00000000:00000000 00:00000000 00000000     0        0 101 1\n"
+	tcpWait   = "   2: 0100007F:1F90 0100007F:C351 06 00000000:00000000 00:00000000 00000000     0        0 0 1\n"
+)
+
+func writeProc(t *testing.T, root, file, content string) {
+	path := filepath.Join(root, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0770); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeCounters(t *testing.T, root string, retrans, overflows, rcvbuf string) {
+	writeProc(t, root, "net/snmp", ""+
+		"Ip: Forwarding DefaultTTL\n"+
+		"Ip: 2 64\n"+
+		"Tcp: RtoAlgorithm RetransSegs OutRsts EstabResets AttemptFails\n"+
+		"Tcp: 1 "+retrans+" 7 3 1\n"+
+		"Udp: InDatagrams InErrors RcvbufErrors SndbufErrors\n"+
+		"Udp: 100 0 "+rcvbuf+" 0\n")
+	writeProc(t, root, "net/netstat", ""+
+		"TcpExt: SyncookiesSent ListenOverflows ListenDrops\n"+
+		"TcpExt: 0 "+overflows+" "+overflows+"\n"+
+		"IpExt: InNoRoutes\n"+
+		"IpExt: 0\n")
+}
+
+func TestCollector_Collect(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	writeProc(t, root, "net/tcp", tcpHeader+tcpListen+tcpEstab+tcpWait)
+	writeProc(t, root, "net/tcp6", tcpHeader+tcpEstab)
+	writeProc(t, root, "sys/fs/file-nr", "1024\t0\t65536\n")
+	writeCounters(t, root, "10", "2", "0")
+
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetProcfsRoot").Return(root)
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	collector, err := New(cfg, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCounters(t, root, "15", "6", "1")
+
+	// Act
+	metrics, err := collector.Collect(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), metrics.Gauge["TCPConnEstablished"])
+	assert.Equal(t, float64(1), metrics.Gauge["TCPConnListen"])
+	assert.Equal(t, float64(1), metrics.Gauge["TCPConnTimeWait"])
+	assert.Equal(t, float64(0), metrics.Gauge["TCPConnCloseWait"])
+	assert.Equal(t, float64(1024), metrics.Gauge["FileDescriptorsAllocated"])
+	assert.Equal(t, float64(65536), metrics.Gauge["FileDescriptorsMax"])
+	assert.Equal(t, int64(5), metrics.Counter["TcpRetransSegs"])
+	assert.Equal(t, int64(0), metrics.Counter["TcpOutRsts"])
+	assert.Equal(t, int64(4), metrics.Counter["TcpExtListenOverflows"])
+	assert.Equal(t, int64(1), metrics.Counter["UdpRcvbufErrors"])
+	assert.NotContains(t, metrics.Counter, "IpExtInNoRoutes")
+}
+
+func TestCollector_CollectMissingFiles(t *testing.T) {
+	// Arrange
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetProcfsRoot").Return(t.TempDir())
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	collector, err := New(cfg, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	metrics, err := collector.Collect(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, metrics.Counter)
+	assert.Equal(t, float64(0), metrics.Gauge["TCPConnEstablished"])
+}
+
+func TestNew_unreadable(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "net", "tcp"), 0770); err != nil {
+		t.Fatal(err)
+	}
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetProcfsRoot").Return(root)
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	log.On("Info", mock.Anything, mock.Anything).Return("")
+
+	// Act
+	collector, err := New(cfg, log)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, collector.disabled)
+	metrics, err := collector.Collect(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, metrics.Gauge)
+	assert.Empty(t, metrics.Counter)
+}
@@ -0,0 +1,118 @@
+// Collector of power supplies and batteries
+package power
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap/zapcore"
+)
+
+const Name = "power"
+
+// battery status as a gauge value
+var statuses = map[string]float64{
+	"Unknown":      0,
+	"Charging":     1,
+	"Discharging":  2,
+	"Not charging": 3,
+	"Full":         4,
+}
+
+// numeric battery attributes and their names in metrics
+var batteryAttributes = map[string]string{
+	"capacity":    "Capacity",
+	"charge_now":  "ChargeNow",
+	"charge_full": "ChargeFull",
+	"energy_now":  "EnergyNow",
+	"energy_full": "EnergyFull",
+	"cycle_count": "CycleCount",
+}
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetSysfsRoot() string
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+}
+
+type Collector struct {
+	root string
+}
+
+func New(config cfg, log log) (*Collector, error) {
+	log.Info("Collector is power")
+
+	return &Collector{
+		root: filepath.Join(config.GetSysfsRoot(), "class", "power_supply"),
+	}, nil
+}
+
+func (c *Collector) Name() string {
+	return Name
+}
+
+func (c *Collector) Collect(ctx context.Context) (entity.MetricsType, error) {
+	metrics := entity.MetricsType{
+		Gauge:   make(entity.GaugeType),
+		Counter: make(entity.CounterType),
+	}
+
+	supplies, err := os.ReadDir(c.root)
+	if err != nil {
+		// no power supply class, e.g. in a container
+		if errors.Is(err, fs.ErrNotExist) {
+			return metrics, nil
+		}
+		return metrics, err
+	}
+
+	for _, supply := range supplies {
+		dir := filepath.Join(c.root, supply.Name())
+
+		switch readAttribute(dir, "type") {
+		case "Battery":
+			prefix := "Battery" + supply.Name()
+			for file, name := range batteryAttributes {
+				if value, ok := readNumber(dir, file); ok {
+					metrics.Gauge[prefix+name] = value
+				}
+			}
+			if status, ok := statuses[readAttribute(dir, "status")]; ok {
+				metrics.Gauge[prefix+"Status"] = status
+			}
+		case "Mains", "USB":
+			if value, ok := readNumber(dir, "online"); ok {
+				metrics.Gauge["PowerSupply"+supply.Name()+"Online"] = value
+			}
+		}
+	}
+
+	return metrics, nil
+}
+
+// readAttribute returns the sysfs value or empty string if it is missing
+func readAttribute(dir, file string) string {
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readNumber(dir, file string) (float64, bool) {
+	value, err := strconv.ParseFloat(readAttribute(dir, file), 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,87 @@
+// Collector of power supplies and batteries
+
+package power
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/power/mocks"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func writeSupply(t *testing.T, root, name string, attributes map[string]string) {
+	dir := filepath.Join(root, "class", "power_supply", name)
+	if err := os.MkdirAll(dir, 0770); err != nil {
+		t.Fatal(err)
+	}
+	for file, value := range attributes {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(value+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	laptop := t.TempDir()
+	writeSupply(t, laptop, "BAT0", map[string]string{
+		"type":        "Battery",
+		"status":      "Discharging",
+		"capacity":    "87",
+		"energy_now":  "43000000",
+		"energy_full": "50000000",
+		"cycle_count": "120",
+	})
+	writeSupply(t, laptop, "AC", map[string]string{
+		"type":   "Mains",
+		"online": "0",
+	})
+	writeSupply(t, laptop, "hidpp_battery_0", map[string]string{
+		"type": "Unknown",
+	})
+
+	tests := []struct {
+		name  string
+		root  string
+		gauge entity.GaugeType
+	}{
+		{
+			name: "laptop",
+			root: laptop,
+			gauge: entity.GaugeType{
+				"BatteryBAT0Status":     2,
+				"BatteryBAT0Capacity":   87,
+				"BatteryBAT0EnergyNow":  43000000,
+				"BatteryBAT0EnergyFull": 50000000,
+				"BatteryBAT0CycleCount": 120,
+				"PowerSupplyACOnline":   0,
+			},
+		},
+		{
+			name:  "no power supplies",
+			root:  t.TempDir(),
+			gauge: entity.GaugeType{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := mocks.NewCfg(t)
+			cfg.On("GetSysfsRoot").Return(tt.root)
+			log := mocks.NewLog(t)
+			log.On("Info", mock.Anything).Return("")
+			collector, _ := New(cfg, log)
+
+			// Act
+			metrics, err := collector.Collect(context.Background())
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.gauge, metrics.Gauge)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Collector of synthetic probes
+package probe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap/zapcore"
+)
+
+const Name = "probe"
+
+const (
+	HTTP = "http"
+	TCP  = "tcp"
+
+	defaultTimeout = 5 * time.Second
+	// only the beginning of the body is matched
+	maxBodySize = 1 << 20 // 1 MB
+)
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetProbeTargets() []entity.ProbeTarget
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+}
+
+type target struct {
+	name      string
+	kind      string
+	address   string
+	bodyRegex *regexp.Regexp
+	timeout   time.Duration
+}
+
+type Collector struct {
+	targets []target
+	client  *http.Client
+	dialer  net.Dialer
+}
+
+func New(config cfg, log log) (*Collector, error) {
+	log.Info("Collector is probe")
+
+	collector := &Collector{
+		client: &http.Client{},
+	}
+
+	for _, t := range config.GetProbeTargets() {
+		if t.Name == "" || t.Address == "" || (t.Type != HTTP && t.Type != TCP) {
+			return nil, fmt.Errorf("probe %s: %w", t.Name, entity.ErrInvalidCollectorRule)
+		}
+
+		probeTarget := target{
+			name:    t.Name,
+			kind:    t.Type,
+			address: t.Address,
+			timeout: time.Duration(t.Timeout) * time.Second,
+		}
+		if probeTarget.timeout <= 0 {
+			probeTarget.timeout = defaultTimeout
+		}
+		if t.BodyRegex != "" {
+			re, err := regexp.Compile(t.BodyRegex)
+			if err != nil {
+				return nil, fmt.Errorf("probe %s: %w", t.Name, err)
+			}
+			probeTarget.bodyRegex = re
+		}
+
+		collector.targets = append(collector.targets, probeTarget)
+	}
+
+	return collector, nil
+}
+
+// Collect runs all probes in parallel
+func (c *Collector) Name() string {
+	return Name
+}
+
+func (c *Collector) Collect(ctx context.Context) (entity.MetricsType, error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	metrics := entity.MetricsType{
+		Gauge:   make(entity.GaugeType),
+		Counter: make(entity.CounterType),
+	}
+
+	for _, t := range c.targets {
+		wg.Add(1)
+		go func(t target) {
+			defer wg.Done()
+
+			var gauge entity.GaugeType
+			switch t.kind {
+			case HTTP:
+				gauge = c.probeHTTP(ctx, t)
+			case TCP:
+				gauge = c.probeTCP(ctx, t)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for name, value := range gauge {
+				metrics.Gauge[t.name+"_"+name] = value
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	return metrics, nil
+}
+
+func (c *Collector) probeHTTP(ctx context.Context, t target) entity.GaugeType {
+	gauge := entity.GaugeType{
+		"success": 0,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.address, http.NoBody)
+	if err != nil {
+		return gauge
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return gauge
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	gauge["latency_ms"] = float64(time.Since(start).Microseconds()) / 1000
+	gauge["status_code"] = float64(resp.StatusCode)
+
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		expiry := time.Until(resp.TLS.PeerCertificates[0].NotAfter)
+		gauge["tls_expiry_days"] = expiry.Hours() / 24
+	}
+
+	success := err == nil && resp.StatusCode >= 200 && resp.StatusCode < 400
+	if t.bodyRegex != nil {
+		match := err == nil && t.bodyRegex.Match(body)
+		gauge["body_match"] = boolToFloat(match)
+		success = success && match
+	}
+	gauge["success"] = boolToFloat(success)
+
+	return gauge
+}
+
+func (c *Collector) probeTCP(ctx context.Context, t target) entity.GaugeType {
+	gauge := entity.GaugeType{
+		"success": 0,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := c.dialer.DialContext(ctx, "tcp", t.address)
+	if err != nil {
+		return gauge
+	}
+	conn.Close()
+
+	gauge["latency_ms"] = float64(time.Since(start).Microseconds()) / 1000
+	gauge["success"] = 1
+
+	return gauge
+}
+
+func boolToFloat(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,152 @@
+// Collector of synthetic probes
+
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/probe/mocks"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []entity.ProbeTarget
+		err     bool
+	}{
+		{
+			name:    "positive",
+			targets: []entity.ProbeTarget{{Name: "api", Type: HTTP, Address: "http://localhost"}},
+		},
+		{
+			name:    "negative type",
+			targets: []entity.ProbeTarget{{Name: "api", Type: "icmp", Address: "localhost"}},
+			err:     true,
+		},
+		{
+			name:    "negative regex",
+			targets: []entity.ProbeTarget{{Name: "api", Type: HTTP, Address: "http://localhost", BodyRegex: "("}},
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := mocks.NewCfg(t)
+			cfg.On("GetProbeTargets").Return(tt.targets)
+			log := mocks.NewLog(t)
+			log.On("Info", mock.Anything).Return("")
+
+			// Act
+			collector, err := New(cfg, log)
+
+			// Assert
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, collector)
+		})
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	httpServer := httptest.NewServer(mux)
+	defer httpServer.Close()
+	tlsServer := httptest.NewTLSServer(mux)
+	defer tlsServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddress := listener.Addr().String()
+	listener.Close()
+
+	tests := []struct {
+		name    string
+		target  entity.ProbeTarget
+		success float64
+		gauges  []string
+	}{
+		{
+			name:    "http ok",
+			target:  entity.ProbeTarget{Name: "api", Type: HTTP, Address: httpServer.URL + "/ok"},
+			success: 1,
+			gauges:  []string{"api_success", "api_latency_ms", "api_status_code"},
+		},
+		{
+			name:    "http body match",
+			target:  entity.ProbeTarget{Name: "api", Type: HTTP, Address: httpServer.URL + "/ok", BodyRegex: `"status":"ok"`},
+			success: 1,
+			gauges:  []string{"api_success", "api_latency_ms", "api_status_code", "api_body_match"},
+		},
+		{
+			name:    "http body mismatch",
+			target:  entity.ProbeTarget{Name: "api", Type: HTTP, Address: httpServer.URL + "/ok", BodyRegex: `"status":"down"`},
+			success: 0,
+			gauges:  []string{"api_success", "api_latency_ms", "api_status_code", "api_body_match"},
+		},
+		{
+			name:    "http error status",
+			target:  entity.ProbeTarget{Name: "api", Type: HTTP, Address: httpServer.URL + "/fail"},
+			success: 0,
+			gauges:  []string{"api_success", "api_latency_ms", "api_status_code"},
+		},
+		{
+			name:    "https tls expiry",
+			target:  entity.ProbeTarget{Name: "api", Type: HTTP, Address: tlsServer.URL + "/ok"},
+			success: 1,
+			gauges:  []string{"api_success", "api_latency_ms", "api_status_code", "api_tls_expiry_days"},
+		},
+		{
+			name:    "tcp ok",
+			target:  entity.ProbeTarget{Name: "db", Type: TCP, Address: httpServer.Listener.Addr().String()},
+			success: 1,
+			gauges:  []string{"db_success", "db_latency_ms"},
+		},
+		{
+			name:    "tcp refused",
+			target:  entity.ProbeTarget{Name: "db", Type: TCP, Address: closedAddress},
+			success: 0,
+			gauges:  []string{"db_success"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := mocks.NewCfg(t)
+			cfg.On("GetProbeTargets").Return([]entity.ProbeTarget{tt.target})
+			log := mocks.NewLog(t)
+			log.On("Info", mock.Anything).Return("")
+			collector, _ := New(cfg, log)
+			collector.client = tlsServer.Client()
+
+			// Act
+			metrics, err := collector.Collect(context.Background())
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.success, metrics.Gauge[tt.target.Name+"_success"])
+			assert.Len(t, metrics.Gauge, len(tt.gauges))
+			for _, name := range tt.gauges {
+				assert.Contains(t, metrics.Gauge, name)
+			}
+		})
+	}
+}
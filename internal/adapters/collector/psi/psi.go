@@ -0,0 +1,151 @@
+// Collector of pressure stall information
+package psi
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const Name = "psi"
+
+// files in /proc/pressure and their names in metrics
+var resources = map[string]string{
+	"cpu":    "CPU",
+	"memory": "Memory",
+	"io":     "IO",
+}
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetProcfsRoot() string
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+}
+
+type Collector struct {
+	mu       sync.Mutex
+	root     string
+	disabled bool
+	// totals of the previous collect, counters are sent as increments
+	totals map[string]int64
+}
+
+func New(config cfg, log log) (*Collector, error) {
+	log.Info("Collector is psi")
+
+	collector := &Collector{
+		root:   filepath.Join(config.GetProcfsRoot(), "pressure"),
+		totals: make(map[string]int64),
+	}
+
+	if _, err := os.Stat(collector.root); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		log.Info("PSI is not supported by the kernel, collector psi is disabled")
+		collector.disabled = true
+		return collector, nil
+	}
+
+	// the first collect only remembers totals,
+	// PSI may be listed but not readable, e.g. the kernel is booted with psi=0
+	if _, err := collector.Collect(context.Background()); err != nil {
+		log.Info("PSI is not readable, collector psi is disabled", zap.Error(err))
+		collector.disabled = true
+	}
+
+	return collector, nil
+}
+
+func (c *Collector) Name() string {
+	return Name
+}
+
+func (c *Collector) Collect(ctx context.Context) (entity.MetricsType, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics := entity.MetricsType{
+		Gauge:   make(entity.GaugeType),
+		Counter: make(entity.CounterType),
+	}
+	if c.disabled {
+		return metrics, nil
+	}
+
+	for file, resource := range resources {
+		if err := c.parse(file, "Pressure"+resource, metrics); err != nil {
+			// some resources are missing on old kernels
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return metrics, err
+		}
+	}
+
+	return metrics, nil
+}
+
+// parse lines like
+// some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func (c *Collector) parse(file, prefix string, metrics entity.MetricsType) error {
+	f, err := os.Open(filepath.Join(c.root, file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		// some -> Some, full -> Full
+		kind := strings.ToUpper(fields[0][:1]) + fields[0][1:]
+
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok || key == "" {
+				continue
+			}
+			name := prefix + kind + strings.ToUpper(key[:1]) + key[1:]
+
+			if key == "total" {
+				total, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return err
+				}
+				if prev, ok := c.totals[name]; ok && total >= prev {
+					metrics.Counter[name] = total - prev
+				} else {
+					metrics.Counter[name] = 0
+				}
+				c.totals[name] = total
+				continue
+			}
+
+			avg, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+			metrics.Gauge[name] = avg
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,135 @@
+// Collector of pressure stall information
+
+package psi
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/collector/psi/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func writePressure(t *testing.T, root, file, content string) {
+	dir := filepath.Join(root, "pressure")
+	if err := os.MkdirAll(dir, 0770); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		pressure   bool
+		unreadable bool
+		disabled   bool
+	}{
+		{
+			name:     "positive",
+			pressure: true,
+		},
+		{
+			name:     "kernel without psi",
+			disabled: true,
+		},
+		{
+			name:       "unreadable psi",
+			unreadable: true,
+			disabled:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			root := t.TempDir()
+			if tt.pressure {
+				writePressure(t, root, "cpu", "some avg10=0.00 avg60=0.00 avg300=0.00 total=0\n")
+			}
+			if tt.unreadable {
+				// reading a directory fails like /proc/pressure of a kernel booted with psi=0
+				if err := os.MkdirAll(filepath.Join(root, "pressure", "cpu"), 0770); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg := mocks.NewCfg(t)
+			cfg.On("GetProcfsRoot").Return(root)
+			log := mocks.NewLog(t)
+			log.On("Info", mock.Anything).Return("")
+			log.On("Info", mock.Anything, mock.Anything).Return("").Maybe()
+
+			// Act
+			collector, err := New(cfg, log)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.disabled, collector.disabled)
+
+			metrics, err := collector.Collect(context.Background())
+			assert.NoError(t, err)
+			if tt.disabled {
+				assert.Empty(t, metrics.Gauge)
+				assert.Empty(t, metrics.Counter)
+			}
+		})
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	root := t.TempDir()
+	writePressure(t, root, "cpu", "some avg10=1.50 avg60=2.00 avg300=3.25 total=1000\n")
+	writePressure(t, root, "memory", "some avg10=0.00 avg60=0.00 avg300=0.00 total=50\nfull avg10=0.10 avg60=0.20 avg300=0.30 total=20\n")
+
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetProcfsRoot").Return(root)
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	collector, err := New(cfg, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Arrange
+	writePressure(t, root, "cpu", "some avg10=4.00 avg60=2.00 avg300=3.25 total=1600\n")
+	writePressure(t, root, "memory", "some avg10=0.00 avg60=0.00 avg300=0.00 total=50\nfull avg10=0.10 avg60=0.20 avg300=0.30 total=25\n")
+
+	// Act
+	metrics, err := collector.Collect(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4.0, metrics.Gauge["PressureCPUSomeAvg10"])
+	assert.Equal(t, 3.25, metrics.Gauge["PressureCPUSomeAvg300"])
+	assert.Equal(t, 0.2, metrics.Gauge["PressureMemoryFullAvg60"])
+	assert.Equal(t, int64(600), metrics.Counter["PressureCPUSomeTotal"])
+	assert.Equal(t, int64(0), metrics.Counter["PressureMemorySomeTotal"])
+	assert.Equal(t, int64(5), metrics.Counter["PressureMemoryFullTotal"])
+	assert.NotContains(t, metrics.Gauge, "PressureIOSomeAvg10")
+}
+
+func TestCollector_Collect_emptyKey(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	writePressure(t, root, "cpu", "some =1.00 avg10=1.50 total=1000\nfull =\n")
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetProcfsRoot").Return(root)
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	collector, err := New(cfg, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	metrics, err := collector.Collect(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, metrics.Gauge["PressureCPUSomeAvg10"])
+	assert.NotContains(t, metrics.Gauge, "PressureCPUSome")
+}
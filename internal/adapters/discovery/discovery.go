@@ -0,0 +1,200 @@
+// Discovery of scrape targets from JSON and YAML files in a directory
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetScrapeSDDir() string
+	GetScrapeSDRefresh() time.Duration
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+	Error(msg string, fields ...zapcore.Field)
+}
+
+type Discovery struct {
+	dir     string
+	refresh time.Duration
+	log     log
+
+	// targets of every file, kept when the file becomes invalid
+	files   map[string][]entity.ScrapeTarget
+	targets []entity.ScrapeTarget
+}
+
+func New(config cfg, log log) (*Discovery, error) {
+	log.Info("Scrape targets are discovered in " + config.GetScrapeSDDir())
+
+	if _, err := os.ReadDir(config.GetScrapeSDDir()); err != nil {
+		return nil, err
+	}
+
+	return &Discovery{
+		dir:     config.GetScrapeSDDir(),
+		refresh: config.GetScrapeSDRefresh(),
+		log:     log,
+		files:   make(map[string][]entity.ScrapeTarget),
+	}, nil
+}
+
+// Run reads the directory at startup, then on every change of a file of targets in it
+// and on every refresh interval as a fallback for file systems without notifications,
+// the full list of targets is sent when it is changed
+func (d *Discovery) Run(ctx context.Context, targets chan<- []entity.ScrapeTarget) {
+	ticker := time.NewTicker(d.refresh)
+	defer ticker.Stop()
+
+	// without the watcher the directory is only read on the ticker
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if watcher, err := d.watch(); err != nil {
+		d.log.Error("watch scrape targets", zap.Error(err))
+	} else {
+		defer watcher.Close()
+		events, errs = watcher.Events, watcher.Errors
+	}
+
+	for {
+		if discovered, changed := d.discover(); changed {
+			select {
+			case targets <- discovered:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				break wait
+			case event, ok := <-events:
+				if !ok {
+					events = nil
+					continue
+				}
+				if isTargetFile(event.Name) {
+					break wait
+				}
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				d.log.Error("watch scrape targets", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (d *Discovery) watch() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(d.dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// discover reads all files of targets,
+// it returns the targets and whether they are changed since the previous call
+func (d *Discovery) discover() ([]entity.ScrapeTarget, bool) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		d.log.Error("read scrape targets", zap.Error(err))
+		return d.targets, false
+	}
+
+	files := make(map[string][]entity.ScrapeTarget)
+	for _, entry := range entries {
+		if entry.IsDir() || !isTargetFile(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(d.dir, entry.Name())
+		fileTargets, err := readFile(path)
+		if err != nil {
+			d.log.Error("read scrape targets "+path, zap.Error(err))
+			fileTargets = d.files[path]
+		}
+		files[path] = fileTargets
+	}
+	d.files = files
+
+	targets := make([]entity.ScrapeTarget, 0)
+	for _, fileTargets := range files {
+		targets = append(targets, fileTargets...)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Address < targets[j].Address
+	})
+
+	if d.targets != nil && reflect.DeepEqual(targets, d.targets) {
+		return d.targets, false
+	}
+	d.targets = targets
+	return targets, true
+}
+
+func isTargetFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// readFile parses groups of targets, the format depends on the file extension
+func readFile(path string) ([]entity.ScrapeTarget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []entity.TargetGroup
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(data, &groups)
+	} else {
+		err = yaml.Unmarshal(data, &groups)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]entity.ScrapeTarget, 0)
+	for _, group := range groups {
+		for _, address := range group.Targets {
+			address = strings.TrimSpace(address)
+			if address == "" {
+				continue
+			}
+			targets = append(targets, entity.ScrapeTarget{
+				Address: address,
+				Labels:  group.Labels,
+			})
+		}
+	}
+	return targets, nil
+}
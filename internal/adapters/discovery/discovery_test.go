@@ -0,0 +1,153 @@
+// Discovery of scrape targets from JSON and YAML files in a directory
+
+package discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/discovery/mocks"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	jsonTargets = `[{"targets": ["10.0.0.5:8081", "10.0.0.6:8081"], "labels": {"group": "web"}}]`
+	yamlTargets = `
+- targets:
+    - 10.0.0.7:8081
+  labels:
+    group: db
+`
+)
+
+func writeFile(t *testing.T, path, data string) {
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newDiscovery(t *testing.T, dir string, refresh time.Duration) (*Discovery, error) {
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetScrapeSDDir").Return(dir)
+	cfg.On("GetScrapeSDRefresh").Return(refresh).Maybe()
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	log.On("Error", mock.Anything, mock.Anything).Return("").Maybe()
+
+	return New(cfg, log)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		err  bool
+	}{
+		{
+			name: "positive",
+			dir:  t.TempDir(),
+		},
+		{
+			name: "negative missing dir",
+			dir:  filepath.Join(t.TempDir(), "missing"),
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			discovery, err := newDiscovery(t, tt.dir, 10*time.Millisecond)
+
+			// Assert
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, discovery)
+		})
+	}
+}
+
+func TestDiscovery_discover(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "web.json"), jsonTargets)
+	writeFile(t, filepath.Join(dir, "db.yml"), yamlTargets)
+	writeFile(t, filepath.Join(dir, "notes.txt"), "10.0.0.8:8081")
+	discovery, _ := newDiscovery(t, dir, 10*time.Millisecond)
+
+	// Act
+	first, firstChanged := discovery.discover()
+	_, sameChanged := discovery.discover()
+	writeFile(t, filepath.Join(dir, "web.json"), "[{")
+	invalid, invalidChanged := discovery.discover()
+	if err := os.Remove(filepath.Join(dir, "db.yml")); err != nil {
+		t.Fatal(err)
+	}
+	removed, removedChanged := discovery.discover()
+
+	// Assert
+	assert.True(t, firstChanged)
+	assert.Equal(t, []entity.ScrapeTarget{
+		{Address: "10.0.0.5:8081", Labels: map[string]string{"group": "web"}},
+		{Address: "10.0.0.6:8081", Labels: map[string]string{"group": "web"}},
+		{Address: "10.0.0.7:8081", Labels: map[string]string{"group": "db"}},
+	}, first)
+	assert.False(t, sameChanged)
+	assert.False(t, invalidChanged)
+	assert.Equal(t, first, invalid)
+	assert.True(t, removedChanged)
+	assert.Len(t, removed, 2)
+}
+
+func TestDiscovery_Run(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	discovery, _ := newDiscovery(t, dir, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	targets := make(chan []entity.ScrapeTarget)
+
+	// Act
+	go discovery.Run(ctx, targets)
+	empty := <-targets
+	writeFile(t, filepath.Join(dir, "web.json"), jsonTargets)
+	discovered := <-targets
+
+	// Assert
+	assert.Empty(t, empty)
+	assert.Len(t, discovered, 2)
+}
+
+func TestDiscovery_Run_watch(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	// the fallback doesn't fire during the test, so the change is found by the watcher
+	discovery, _ := newDiscovery(t, dir, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	targets := make(chan []entity.ScrapeTarget)
+
+	// Act
+	go discovery.Run(ctx, targets)
+	empty := <-targets
+	writeFile(t, filepath.Join(dir, "notes.txt"), "10.0.0.8:8081")
+	writeFile(t, filepath.Join(dir, "db.yml"), yamlTargets)
+
+	// Assert
+	assert.Empty(t, empty)
+	select {
+	case discovered := <-targets:
+		assert.Equal(t, []entity.ScrapeTarget{
+			{Address: "10.0.0.7:8081", Labels: map[string]string{"group": "db"}},
+		}, discovered)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change of the directory is not discovered")
+	}
+}
@@ -6,20 +6,39 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/storage/history"
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 	"go.uber.org/zap/zapcore"
 )
 
 type Storage struct {
-	filePath string
-	metrics  entity.MetricsType
+	filePath  string
+	metrics   entity.MetricsType
+	metricsMu sync.RWMutex
+	hosts     map[string]entity.HostInfo
+	hostsMu   sync.RWMutex
+	agents    map[string]entity.Agent
+	agentsMu  sync.RWMutex
+	// the history isn't written to the file
+	history *history.History
+}
+
+// content of the file, metrics fields stay on the top level
+// so files written before hosts were added are still loaded
+type fileData struct {
+	entity.MetricsType
+	Hosts  map[string]entity.HostInfo `json:"Hosts,omitempty"`
+	Agents map[string]entity.Agent    `json:"Agents,omitempty"`
 }
 
 //go:generate mockery --name cfg --exported
 type cfg interface {
 	GetFileStoragePath() string
 	GetRestore() bool
+	GetHistorySize() int
 }
 
 //go:generate mockery --name log --exported
@@ -33,9 +52,14 @@ func New(config cfg, log log) (*Storage, error) {
 	storage := &Storage{
 		filePath: config.GetFileStoragePath(),
 		metrics: entity.MetricsType{
-			Gauge:   make(entity.GaugeType),
-			Counter: make(entity.CounterType),
+			Gauge:     make(entity.GaugeType),
+			Counter:   make(entity.CounterType),
+			Histogram: make(entity.HistogramType),
+			Summary:   make(entity.SummaryType),
 		},
+		hosts:   make(map[string]entity.HostInfo),
+		agents:  make(map[string]entity.Agent),
+		history: history.New(config.GetHistorySize()),
 	}
 	// create dir
 	if err := os.MkdirAll(filepath.Dir(storage.filePath), 0770); err != nil {
@@ -49,26 +73,68 @@ func New(config cfg, log log) (*Storage, error) {
 	defer file.Close()
 
 	if config.GetRestore() {
-		metrics, err := storage.loadFromFile()
+		data, err := storage.loadFromFile()
 		if err != nil {
 			return storage, nil
 		}
-		storage.metrics = metrics
+		storage.metrics = data.MetricsType
+		// files written before histograms and summaries were added
+		if storage.metrics.Histogram == nil {
+			storage.metrics.Histogram = make(entity.HistogramType)
+		}
+		if storage.metrics.Summary == nil {
+			storage.metrics.Summary = make(entity.SummaryType)
+		}
+		if data.Hosts != nil {
+			storage.hosts = data.Hosts
+		}
+		if data.Agents != nil {
+			storage.agents = data.Agents
+		}
 	}
 
 	return storage, nil
 }
 
+// SaveAllData saves the metrics, histograms and summaries are merged with the stored ones,
+// and writes all data to the file
 func (s *Storage) SaveAllData(ctx context.Context, metrics []entity.Metrics) error {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
+	now := time.Now()
+	for _, metric := range metrics {
+		switch metric.MType {
+		case entity.GaugeMetric:
+			s.metrics.Gauge[metric.ID] = *metric.Value
+			s.history.Add(entity.GaugeMetric, metric.ID, *metric.Value, now)
+		case entity.CounterMetric:
+			s.metrics.Counter[metric.ID] = *metric.Delta
+			s.history.Add(entity.CounterMetric, metric.ID, float64(*metric.Delta), now)
+		case entity.HistogramMetric:
+			s.metrics.Histogram[metric.ID] = s.metrics.Histogram[metric.ID].Merge(*metric.Histogram)
+		case entity.SummaryMetric:
+			s.metrics.Summary[metric.ID] = s.metrics.Summary[metric.ID].Merge(*metric.Summary)
+		default:
+			return entity.ErrInputVarIsWrongType
+		}
+	}
 	return s.saveToFile()
 }
 
 func (s *Storage) SaveGauge(ctx context.Context, gaugeName string, gaugeValue float64) error {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
 	s.metrics.Gauge[gaugeName] = gaugeValue
+	s.history.Add(entity.GaugeMetric, gaugeName, gaugeValue, time.Now())
 	return nil
 }
 
 func (s *Storage) GetGauge(ctx context.Context, gaugeName string) (float64, error) {
+	s.metricsMu.RLock()
+	defer s.metricsMu.RUnlock()
+
 	val, ok := s.metrics.Gauge[gaugeName]
 	if !ok {
 		return val, entity.ErrMetricNotFound
@@ -77,11 +143,42 @@ func (s *Storage) GetGauge(ctx context.Context, gaugeName string) (float64, erro
 }
 
 func (s *Storage) SaveCounter(ctx context.Context, counterName string, counterValue int64) error {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
 	s.metrics.Counter[counterName] = counterValue
+	s.history.Add(entity.CounterMetric, counterName, float64(counterValue), time.Now())
 	return nil
 }
 
+// IncrementCounter adds the delta to the counter under the lock, so concurrent increments aren't lost
+func (s *Storage) IncrementCounter(ctx context.Context, counterName string, delta int64) (int64, error) {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
+	counterValue := s.metrics.Counter[counterName] + delta
+	s.metrics.Counter[counterName] = counterValue
+	s.history.Add(entity.CounterMetric, counterName, float64(counterValue), time.Now())
+
+	return counterValue, nil
+}
+
+// AddGauge adds the value to the gauge under the lock, so concurrent additions aren't lost
+func (s *Storage) AddGauge(ctx context.Context, gaugeName string, value float64) (float64, error) {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
+	gaugeValue := s.metrics.Gauge[gaugeName] + value
+	s.metrics.Gauge[gaugeName] = gaugeValue
+	s.history.Add(entity.GaugeMetric, gaugeName, gaugeValue, time.Now())
+
+	return gaugeValue, nil
+}
+
 func (s *Storage) GetCounter(ctx context.Context, counterName string) (int64, error) {
+	s.metricsMu.RLock()
+	defer s.metricsMu.RUnlock()
+
 	val, ok := s.metrics.Counter[counterName]
 	if !ok {
 		return val, entity.ErrMetricNotFound
@@ -89,31 +186,149 @@ func (s *Storage) GetCounter(ctx context.Context, counterName string) (int64, er
 	return val, nil
 }
 
+func (s *Storage) SaveHistogram(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) error {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
+	s.metrics.Histogram[histogramName] = histogramValue
+	return nil
+}
+
+func (s *Storage) GetHistogram(ctx context.Context, histogramName string) (entity.HistogramValue, error) {
+	s.metricsMu.RLock()
+	defer s.metricsMu.RUnlock()
+
+	val, ok := s.metrics.Histogram[histogramName]
+	if !ok {
+		return val, entity.ErrMetricNotFound
+	}
+	return val, nil
+}
+
+// AddHistogram merges the value with the stored histogram under the lock, so concurrent additions aren't lost
+func (s *Storage) AddHistogram(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) (entity.HistogramValue, error) {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
+	merged := s.metrics.Histogram[histogramName].Merge(histogramValue)
+	s.metrics.Histogram[histogramName] = merged
+
+	return merged, nil
+}
+
+func (s *Storage) SaveSummary(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) error {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
+	s.metrics.Summary[summaryName] = summaryValue
+	return nil
+}
+
+func (s *Storage) GetSummary(ctx context.Context, summaryName string) (entity.SummaryValue, error) {
+	s.metricsMu.RLock()
+	defer s.metricsMu.RUnlock()
+
+	val, ok := s.metrics.Summary[summaryName]
+	if !ok {
+		return val, entity.ErrMetricNotFound
+	}
+	return val, nil
+}
+
+// AddSummary merges the value with the stored summary under the lock, so concurrent additions aren't lost
+func (s *Storage) AddSummary(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) (entity.SummaryValue, error) {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
+	merged := s.metrics.Summary[summaryName].Merge(summaryValue)
+	s.metrics.Summary[summaryName] = merged
+
+	return merged, nil
+}
+
+// GetHistory returns samples kept in RAM, only the last samples of every series are kept
+func (s *Storage) GetHistory(ctx context.Context, metricType, metricName string, from, to time.Time) ([]entity.Sample, error) {
+	return s.history.Range(metricType, metricName, from, to), nil
+}
+
 func (s *Storage) GetAllData(ctx context.Context) (entity.MetricsType, error) {
-	return s.metrics, nil
+	s.metricsMu.RLock()
+	defer s.metricsMu.RUnlock()
+
+	return s.metrics.Copy(), nil
+}
+
+func (s *Storage) SaveHostInfo(ctx context.Context, hostInfo entity.HostInfo) error {
+	s.hostsMu.Lock()
+	defer s.hostsMu.Unlock()
+
+	s.hosts[hostInfo.Hostname] = hostInfo
+	return nil
 }
 
-func (s *Storage) loadFromFile() (entity.MetricsType, error) {
+func (s *Storage) GetAllHostInfo(ctx context.Context) ([]entity.HostInfo, error) {
+	s.hostsMu.RLock()
+	defer s.hostsMu.RUnlock()
+
+	hosts := make([]entity.HostInfo, 0, len(s.hosts))
+	for _, hostInfo := range s.hosts {
+		hosts = append(hosts, hostInfo)
+	}
+	return hosts, nil
+}
+
+// SaveAgent keeps the first seen time, the config and adds the received metrics
+func (s *Storage) SaveAgent(ctx context.Context, agent entity.Agent) error {
+	s.agentsMu.Lock()
+	defer s.agentsMu.Unlock()
+
+	if saved, ok := s.agents[agent.ID]; ok {
+		agent.FirstSeen = saved.FirstSeen
+		agent.Metrics += saved.Metrics
+		agent.Config = saved.Config
+	}
+	s.agents[agent.ID] = agent
+	return nil
+}
+
+// SaveAgentConfig saves the effective config of a registered agent
+func (s *Storage) SaveAgentConfig(ctx context.Context, agentID string, agentConfig entity.AgentConfig) error {
+	s.agentsMu.Lock()
+	defer s.agentsMu.Unlock()
+
+	if agent, ok := s.agents[agentID]; ok {
+		agent.Config = &agentConfig
+		s.agents[agentID] = agent
+	}
+	return nil
+}
+
+func (s *Storage) GetAllAgents(ctx context.Context) ([]entity.Agent, error) {
+	s.agentsMu.RLock()
+	defer s.agentsMu.RUnlock()
+
+	agents := make([]entity.Agent, 0, len(s.agents))
+	for _, agent := range s.agents {
+		agents = append(agents, agent)
+	}
+	return agents, nil
+}
+
+func (s *Storage) loadFromFile() (fileData, error) {
 	file, err := os.OpenFile(s.filePath, os.O_RDONLY, 0666)
 	if err != nil {
-		return entity.MetricsType{
-			Gauge:   make(entity.GaugeType),
-			Counter: make(entity.CounterType),
-		}, err
+		return fileData{}, err
 	}
 	defer file.Close()
 
-	var metrics entity.MetricsType
+	var data fileData
 	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&metrics)
+	err = decoder.Decode(&data)
 	if err != nil {
-		return entity.MetricsType{
-			Gauge:   make(entity.GaugeType),
-			Counter: make(entity.CounterType),
-		}, err
+		return fileData{}, err
 	}
 
-	return metrics, nil
+	return data, nil
 }
 
 func (s *Storage) saveToFile() error {
@@ -123,8 +338,17 @@ func (s *Storage) saveToFile() error {
 	}
 	defer file.Close()
 
+	s.hostsMu.RLock()
+	defer s.hostsMu.RUnlock()
+	s.agentsMu.RLock()
+	defer s.agentsMu.RUnlock()
+
 	encoder := json.NewEncoder(file)
-	err = encoder.Encode(s.metrics)
+	err = encoder.Encode(fileData{
+		MetricsType: s.metrics,
+		Hosts:       s.hosts,
+		Agents:      s.agents,
+	})
 	if err != nil {
 		return err
 	}
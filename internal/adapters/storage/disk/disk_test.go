@@ -17,6 +17,7 @@ const diskPath = "./disk"
 
 func TestNewMemoryStorage(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	cfg.On("GetFileStoragePath").Return(diskPath).Maybe()
 	cfg.On("GetRestore").Return(false).Maybe()
 
@@ -32,6 +33,7 @@ func TestNewMemoryStorage(t *testing.T) {
 
 func TestStorage_SaveAllData(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	cfg.On("GetFileStoragePath").Return(diskPath).Maybe()
 	cfg.On("GetRestore").Return(false).Maybe()
 
@@ -104,6 +106,7 @@ func TestStorage_SaveAllData(t *testing.T) {
 
 func TestStorage_GetAllData(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	cfg.On("GetFileStoragePath").Return(diskPath).Maybe()
 	cfg.On("GetRestore").Return(false).Maybe()
 
@@ -139,6 +142,7 @@ func TestStorage_GetAllData(t *testing.T) {
 
 func TestStorage_GetCounter(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	cfg.On("GetFileStoragePath").Return(diskPath).Maybe()
 	cfg.On("GetRestore").Return(false).Maybe()
 
@@ -194,6 +198,7 @@ func TestStorage_GetCounter(t *testing.T) {
 
 func TestStorage_SaveCounter(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	cfg.On("GetFileStoragePath").Return(diskPath).Maybe()
 	cfg.On("GetRestore").Return(false).Maybe()
 
@@ -242,6 +247,7 @@ func TestStorage_SaveCounter(t *testing.T) {
 
 func TestStorage_GetGauge(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	cfg.On("GetFileStoragePath").Return(diskPath).Maybe()
 	cfg.On("GetRestore").Return(false).Maybe()
 
@@ -297,6 +303,7 @@ func TestStorage_GetGauge(t *testing.T) {
 
 func TestStorage_SaveGauge(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	cfg.On("GetFileStoragePath").Return(diskPath).Maybe()
 	cfg.On("GetRestore").Return(false).Maybe()
 
@@ -348,6 +355,7 @@ func TestStorage_Ping(t *testing.T) {
 	log.On("Info", mock.Anything).Return("")
 
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	cfg.On("GetFileStoragePath").Return("").Maybe()
 	cfg.On("GetRestore").Return(false).Maybe()
 
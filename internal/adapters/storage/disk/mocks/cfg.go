@@ -27,6 +27,24 @@ func (_m *Cfg) GetFileStoragePath() string {
 	return r0
 }
 
+// GetHistorySize provides a mock function with given fields:
+func (_m *Cfg) GetHistorySize() int {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHistorySize")
+	}
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
 // GetRestore provides a mock function with given fields:
 func (_m *Cfg) GetRestore() bool {
 	ret := _m.Called()
@@ -0,0 +1,84 @@
+// Bounded history of gauges and counters in RAM
+package history
+
+import (
+	"sync"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+)
+
+type key struct {
+	metricType string
+	name       string
+}
+
+// ring keeps the last samples of a series, the oldest sample is overwritten
+type ring struct {
+	samples []entity.Sample
+	next    int
+}
+
+type History struct {
+	size   int
+	mu     sync.RWMutex
+	series map[key]*ring
+}
+
+// New creates the history with size samples for every series, size 0 disables it
+func New(size int) *History {
+	return &History{
+		size:   size,
+		series: make(map[key]*ring),
+	}
+}
+
+func (h *History) Add(metricType, name string, value float64, t time.Time) {
+	if h.size <= 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.series[key{metricType, name}]
+	if !ok {
+		r = &ring{samples: make([]entity.Sample, 0, min(h.size, 64))}
+		h.series[key{metricType, name}] = r
+	}
+	sample := entity.Sample{Time: t, Value: value}
+	if len(r.samples) < h.size {
+		r.samples = append(r.samples, sample)
+		return
+	}
+	r.samples[r.next] = sample
+	r.next = (r.next + 1) % h.size
+}
+
+// Range returns samples between from and to ordered by time,
+// the latest sample before from goes first, so the increase of a counter can be calculated
+func (h *History) Range(metricType, name string, from, to time.Time) []entity.Sample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	r, ok := h.series[key{metricType, name}]
+	if !ok {
+		return nil
+	}
+
+	var samples []entity.Sample
+	var before *entity.Sample
+	for i := range r.samples {
+		sample := r.samples[(r.next+i)%len(r.samples)]
+		switch {
+		case sample.Time.Before(from):
+			before = &sample
+		case !sample.Time.After(to):
+			samples = append(samples, sample)
+		}
+	}
+	if before != nil {
+		samples = append([]entity.Sample{*before}, samples...)
+	}
+	return samples
+}
@@ -0,0 +1,66 @@
+// Bounded history of gauges and counters in RAM
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory_Range(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	tests := []struct {
+		name   string
+		size   int
+		values []float64
+		from   time.Time
+		to     time.Time
+		want   []entity.Sample
+	}{
+		{
+			name:   "positive",
+			size:   10,
+			values: []float64{1, 2, 3, 4},
+			from:   at(1),
+			to:     at(2),
+			want:   []entity.Sample{{Time: at(0), Value: 1}, {Time: at(1), Value: 2}, {Time: at(2), Value: 3}},
+		},
+		{
+			name:   "oldest samples are overwritten",
+			size:   3,
+			values: []float64{1, 2, 3, 4, 5},
+			from:   at(0),
+			to:     at(10),
+			want:   []entity.Sample{{Time: at(2), Value: 3}, {Time: at(3), Value: 4}, {Time: at(4), Value: 5}},
+		},
+		{
+			name:   "disabled",
+			values: []float64{1, 2},
+			from:   at(0),
+			to:     at(10),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			history := New(tt.size)
+			for i, value := range tt.values {
+				history.Add(entity.GaugeMetric, "load", value, at(i))
+			}
+
+			// Act
+			samples := history.Range(entity.GaugeMetric, "load", tt.from, tt.to)
+
+			// Assert
+			assert.Equal(t, tt.want, samples)
+			assert.Empty(t, history.Range(entity.CounterMetric, "load", tt.from, tt.to))
+		})
+	}
+}
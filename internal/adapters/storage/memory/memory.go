@@ -4,13 +4,22 @@ package memory
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
+	"github.com/korovindenis/go-pc-metrics/internal/adapters/storage/history"
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 	"go.uber.org/zap/zapcore"
 )
 
 type Storage struct {
 	MetricsType entity.MetricsType
+	metricsMu   sync.RWMutex
+	Hosts       map[string]entity.HostInfo
+	hostsMu     sync.RWMutex
+	Agents      map[string]entity.Agent
+	agentsMu    sync.RWMutex
+	history     *history.History
 }
 
 //go:generate mockery --name log --exported
@@ -20,6 +29,7 @@ type log interface {
 
 //go:generate mockery --name cfg --exported
 type cfg interface {
+	GetHistorySize() int
 }
 
 func New(config cfg, log log) (*Storage, error) {
@@ -27,21 +37,33 @@ func New(config cfg, log log) (*Storage, error) {
 
 	storage := Storage{
 		MetricsType: entity.MetricsType{
-			Gauge:   make(map[string]float64),
-			Counter: make(map[string]int64),
+			Gauge:     make(entity.GaugeType),
+			Counter:   make(entity.CounterType),
+			Histogram: make(entity.HistogramType),
+			Summary:   make(entity.SummaryType),
 		},
+		Hosts:   make(map[string]entity.HostInfo),
+		Agents:  make(map[string]entity.Agent),
+		history: history.New(config.GetHistorySize()),
 	}
 
 	return &storage, nil
 }
 
 func (m *Storage) SaveGauge(ctx context.Context, gaugeName string, gaugeValue float64) error {
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+
 	m.MetricsType.Gauge[gaugeName] = gaugeValue
+	m.history.Add(entity.GaugeMetric, gaugeName, gaugeValue, time.Now())
 
 	return nil
 }
 
 func (m *Storage) GetGauge(ctx context.Context, gaugeName string) (float64, error) {
+	m.metricsMu.RLock()
+	defer m.metricsMu.RUnlock()
+
 	val, ok := m.MetricsType.Gauge[gaugeName]
 	if !ok {
 		return val, entity.ErrMetricNotFound
@@ -50,12 +72,43 @@ func (m *Storage) GetGauge(ctx context.Context, gaugeName string) (float64, erro
 }
 
 func (m *Storage) SaveCounter(ctx context.Context, counterName string, counterValue int64) error {
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+
 	m.MetricsType.Counter[counterName] = counterValue
+	m.history.Add(entity.CounterMetric, counterName, float64(counterValue), time.Now())
 
 	return nil
 }
 
+// IncrementCounter adds the delta to the counter under the lock, so concurrent increments aren't lost
+func (m *Storage) IncrementCounter(ctx context.Context, counterName string, delta int64) (int64, error) {
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+
+	counterValue := m.MetricsType.Counter[counterName] + delta
+	m.MetricsType.Counter[counterName] = counterValue
+	m.history.Add(entity.CounterMetric, counterName, float64(counterValue), time.Now())
+
+	return counterValue, nil
+}
+
+// AddGauge adds the value to the gauge under the lock, so concurrent additions aren't lost
+func (m *Storage) AddGauge(ctx context.Context, gaugeName string, value float64) (float64, error) {
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+
+	gaugeValue := m.MetricsType.Gauge[gaugeName] + value
+	m.MetricsType.Gauge[gaugeName] = gaugeValue
+	m.history.Add(entity.GaugeMetric, gaugeName, gaugeValue, time.Now())
+
+	return gaugeValue, nil
+}
+
 func (m *Storage) GetCounter(ctx context.Context, counterName string) (int64, error) {
+	m.metricsMu.RLock()
+	defer m.metricsMu.RUnlock()
+
 	val, ok := m.MetricsType.Counter[counterName]
 	if !ok {
 		return val, entity.ErrMetricNotFound
@@ -64,17 +117,97 @@ func (m *Storage) GetCounter(ctx context.Context, counterName string) (int64, er
 
 }
 
+func (m *Storage) SaveHistogram(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) error {
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+
+	m.MetricsType.Histogram[histogramName] = histogramValue
+
+	return nil
+}
+
+func (m *Storage) GetHistogram(ctx context.Context, histogramName string) (entity.HistogramValue, error) {
+	m.metricsMu.RLock()
+	defer m.metricsMu.RUnlock()
+
+	val, ok := m.MetricsType.Histogram[histogramName]
+	if !ok {
+		return val, entity.ErrMetricNotFound
+	}
+	return val, nil
+}
+
+// AddHistogram merges the value with the stored histogram under the lock, so concurrent additions aren't lost
+func (m *Storage) AddHistogram(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) (entity.HistogramValue, error) {
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+
+	merged := m.MetricsType.Histogram[histogramName].Merge(histogramValue)
+	m.MetricsType.Histogram[histogramName] = merged
+
+	return merged, nil
+}
+
+func (m *Storage) SaveSummary(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) error {
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+
+	m.MetricsType.Summary[summaryName] = summaryValue
+
+	return nil
+}
+
+func (m *Storage) GetSummary(ctx context.Context, summaryName string) (entity.SummaryValue, error) {
+	m.metricsMu.RLock()
+	defer m.metricsMu.RUnlock()
+
+	val, ok := m.MetricsType.Summary[summaryName]
+	if !ok {
+		return val, entity.ErrMetricNotFound
+	}
+	return val, nil
+}
+
+// AddSummary merges the value with the stored summary under the lock, so concurrent additions aren't lost
+func (m *Storage) AddSummary(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) (entity.SummaryValue, error) {
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+
+	merged := m.MetricsType.Summary[summaryName].Merge(summaryValue)
+	m.MetricsType.Summary[summaryName] = merged
+
+	return merged, nil
+}
+
+// GetHistory returns samples kept in RAM, only the last samples of every series are kept
+func (m *Storage) GetHistory(ctx context.Context, metricType, metricName string, from, to time.Time) ([]entity.Sample, error) {
+	return m.history.Range(metricType, metricName, from, to), nil
+}
+
 func (m *Storage) GetAllData(ctx context.Context) (entity.MetricsType, error) {
-	return m.MetricsType, nil
+	m.metricsMu.RLock()
+	defer m.metricsMu.RUnlock()
+
+	return m.MetricsType.Copy(), nil
 }
 
 func (m *Storage) SaveAllData(ctx context.Context, metrics []entity.Metrics) error {
+	m.metricsMu.Lock()
+	defer m.metricsMu.Unlock()
+
+	now := time.Now()
 	for _, metric := range metrics {
 		switch metric.MType {
-		case "gauge":
+		case entity.GaugeMetric:
 			m.MetricsType.Gauge[metric.ID] = *metric.Value
-		case "counter":
+			m.history.Add(entity.GaugeMetric, metric.ID, *metric.Value, now)
+		case entity.CounterMetric:
 			m.MetricsType.Counter[metric.ID] = *metric.Delta
+			m.history.Add(entity.CounterMetric, metric.ID, float64(*metric.Delta), now)
+		case entity.HistogramMetric:
+			m.MetricsType.Histogram[metric.ID] = m.MetricsType.Histogram[metric.ID].Merge(*metric.Histogram)
+		case entity.SummaryMetric:
+			m.MetricsType.Summary[metric.ID] = m.MetricsType.Summary[metric.ID].Merge(*metric.Summary)
 		default:
 			return errors.New("sendMetrics(): metricsVal not recognized")
 		}
@@ -82,6 +215,62 @@ func (m *Storage) SaveAllData(ctx context.Context, metrics []entity.Metrics) err
 	return nil
 }
 
+func (m *Storage) SaveHostInfo(ctx context.Context, hostInfo entity.HostInfo) error {
+	m.hostsMu.Lock()
+	defer m.hostsMu.Unlock()
+
+	m.Hosts[hostInfo.Hostname] = hostInfo
+	return nil
+}
+
+func (m *Storage) GetAllHostInfo(ctx context.Context) ([]entity.HostInfo, error) {
+	m.hostsMu.RLock()
+	defer m.hostsMu.RUnlock()
+
+	hosts := make([]entity.HostInfo, 0, len(m.Hosts))
+	for _, hostInfo := range m.Hosts {
+		hosts = append(hosts, hostInfo)
+	}
+	return hosts, nil
+}
+
+// SaveAgent keeps the first seen time, the config and adds the received metrics
+func (m *Storage) SaveAgent(ctx context.Context, agent entity.Agent) error {
+	m.agentsMu.Lock()
+	defer m.agentsMu.Unlock()
+
+	if saved, ok := m.Agents[agent.ID]; ok {
+		agent.FirstSeen = saved.FirstSeen
+		agent.Metrics += saved.Metrics
+		agent.Config = saved.Config
+	}
+	m.Agents[agent.ID] = agent
+	return nil
+}
+
+// SaveAgentConfig saves the effective config of a registered agent
+func (m *Storage) SaveAgentConfig(ctx context.Context, agentID string, agentConfig entity.AgentConfig) error {
+	m.agentsMu.Lock()
+	defer m.agentsMu.Unlock()
+
+	if agent, ok := m.Agents[agentID]; ok {
+		agent.Config = &agentConfig
+		m.Agents[agentID] = agent
+	}
+	return nil
+}
+
+func (m *Storage) GetAllAgents(ctx context.Context) ([]entity.Agent, error) {
+	m.agentsMu.RLock()
+	defer m.agentsMu.RUnlock()
+
+	agents := make([]entity.Agent, 0, len(m.Agents))
+	for _, agent := range m.Agents {
+		agents = append(agents, agent)
+	}
+	return agents, nil
+}
+
 func (m *Storage) Ping(ctx context.Context) error {
 	return nil
 }
@@ -5,7 +5,10 @@ package memory
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/korovindenis/go-pc-metrics/internal/adapters/storage/memory/mocks"
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
@@ -15,6 +18,7 @@ import (
 
 func TestNewMemoryStorage(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	log := mocks.NewLog(t)
 	log.On("Info", mock.Anything).Return("")
 	storage, err := New(cfg, log)
@@ -26,6 +30,7 @@ func TestNewMemoryStorage(t *testing.T) {
 }
 func TestStorage_SaveAllData(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	log := mocks.NewLog(t)
 	log.On("Info", mock.Anything).Return("")
 	memory, _ := New(cfg, log)
@@ -93,6 +98,7 @@ func TestStorage_SaveAllData(t *testing.T) {
 
 func TestStorage_GetAllData(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	log := mocks.NewLog(t)
 	log.On("Info", mock.Anything).Return("")
 	memory, _ := New(cfg, log)
@@ -121,8 +127,69 @@ func TestStorage_GetAllData(t *testing.T) {
 	}
 }
 
+func TestStorage_GetAllData_concurrent(t *testing.T) {
+	// Arrange
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	memory, _ := New(cfg, log)
+	ctx := context.Background()
+	memory.SaveGauge(ctx, "cpu", 1)
+	done := make(chan struct{})
+
+	// Act
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			memory.SaveGauge(ctx, fmt.Sprintf("cpu%d", i), float64(i))
+		}
+	}()
+	var got entity.MetricsType
+	for i := 0; i < 100; i++ {
+		got, _ = memory.GetAllData(ctx)
+		for range got.Gauge {
+		}
+	}
+	<-done
+	got.Gauge["cpu"] = 2
+
+	// Assert
+	gaugeValue, _ := memory.GetGauge(ctx, "cpu")
+	assert.Equal(t, 1.0, gaugeValue, "the returned maps are copies")
+}
+
+func TestStorage_AddHistogram_concurrent(t *testing.T) {
+	// Arrange
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	memory, _ := New(cfg, log)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			memory.AddHistogram(ctx, "latency", entity.HistogramValue{Bounds: []float64{1}, Counts: []uint64{1, 0}, Sum: 0.5, Count: 1})
+			memory.AddSummary(ctx, "latency", entity.SummaryValue{Sum: 0.5, Count: 1})
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	histogramValue, _ := memory.GetHistogram(ctx, "latency")
+	assert.Equal(t, entity.HistogramValue{Bounds: []float64{1}, Counts: []uint64{100, 0}, Sum: 50, Count: 100}, histogramValue)
+	summaryValue, _ := memory.GetSummary(ctx, "latency")
+	assert.Equal(t, entity.SummaryValue{Sum: 50, Count: 100}, summaryValue)
+}
+
 func TestStorage_GetCounter(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	log := mocks.NewLog(t)
 	log.On("Info", mock.Anything).Return("")
 	memory, _ := New(cfg, log)
@@ -173,6 +240,7 @@ func TestStorage_GetCounter(t *testing.T) {
 
 func TestStorage_SaveCounter(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	log := mocks.NewLog(t)
 	log.On("Info", mock.Anything).Return("")
 	memory, _ := New(cfg, log)
@@ -214,8 +282,65 @@ func TestStorage_SaveCounter(t *testing.T) {
 	}
 }
 
+func TestStorage_IncrementCounter(t *testing.T) {
+	// Arrange
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	memory, _ := New(cfg, log)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			memory.IncrementCounter(ctx, "requests", 2)
+		}()
+	}
+	wg.Wait()
+	got, err := memory.IncrementCounter(ctx, "requests", 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), got)
+	counterValue, _ := memory.GetCounter(ctx, "requests")
+	assert.Equal(t, int64(101), counterValue)
+}
+
+func TestStorage_AddGauge(t *testing.T) {
+	// Arrange
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	memory, _ := New(cfg, log)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			memory.AddGauge(ctx, "duration_sum", 0.5)
+		}()
+	}
+	wg.Wait()
+	got, err := memory.AddGauge(ctx, "duration_sum", 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 26.0, got)
+	gaugeValue, _ := memory.GetGauge(ctx, "duration_sum")
+	assert.Equal(t, 26.0, gaugeValue)
+}
+
 func TestStorage_GetGauge(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	log := mocks.NewLog(t)
 	log.On("Info", mock.Anything).Return("")
 	memory, _ := New(cfg, log)
@@ -266,6 +391,7 @@ func TestStorage_GetGauge(t *testing.T) {
 
 func TestStorage_SaveGauge(t *testing.T) {
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	log := mocks.NewLog(t)
 	log.On("Info", mock.Anything).Return("")
 	memory, _ := New(cfg, log)
@@ -312,6 +438,7 @@ func TestStorage_Ping(t *testing.T) {
 	log.On("Info", mock.Anything).Return("")
 
 	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
 	ctx := context.Background()
 	memory, _ := New(cfg, log)
 
@@ -335,3 +462,109 @@ func TestStorage_Ping(t *testing.T) {
 		})
 	}
 }
+
+func TestStorage_SaveAgent(t *testing.T) {
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
+	ctx := context.Background()
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := first.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		agents []entity.Agent
+		want   []entity.Agent
+	}{
+		{
+			name: "new agent",
+			agents: []entity.Agent{
+				{ID: "a", Version: "1.0", Metrics: 30, FirstSeen: first, LastSeen: first},
+			},
+			want: []entity.Agent{
+				{ID: "a", Version: "1.0", Metrics: 30, FirstSeen: first, LastSeen: first},
+			},
+		},
+		{
+			name: "heartbeat keeps first seen",
+			agents: []entity.Agent{
+				{ID: "a", Version: "1.0", Metrics: 30, FirstSeen: first, LastSeen: first},
+				{ID: "a", Version: "1.1", Metrics: 2, FirstSeen: last, LastSeen: last},
+			},
+			want: []entity.Agent{
+				{ID: "a", Version: "1.1", Metrics: 32, FirstSeen: first, LastSeen: last},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			memory, _ := New(cfg, log)
+
+			// Act
+			for _, agent := range tt.agents {
+				if err := memory.SaveAgent(ctx, agent); err != nil {
+					t.Fatal(err)
+				}
+			}
+			agents, err := memory.GetAllAgents(ctx)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, agents)
+		})
+	}
+}
+
+func TestStorage_SaveAllData_histogram(t *testing.T) {
+	// Arrange
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(100).Maybe()
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	memory, _ := New(cfg, log)
+	ctx := context.Background()
+	histogram := entity.HistogramValue{Bounds: []float64{1}, Counts: []uint64{1, 1}, Sum: 2.5, Count: 2}
+	summary := entity.SummaryValue{Quantiles: []entity.Quantile{{Quantile: 0.5, Value: 1}}, Sum: 2.5, Count: 2}
+
+	// Act
+	err := memory.SaveAllData(ctx, []entity.Metrics{
+		{ID: "latency", MType: entity.HistogramMetric, Histogram: &histogram},
+		{ID: "latency", MType: entity.SummaryMetric, Summary: &summary},
+	})
+	gotHistogram, histogramErr := memory.GetHistogram(ctx, "latency")
+	gotSummary, summaryErr := memory.GetSummary(ctx, "latency")
+	_, notFoundErr := memory.GetHistogram(ctx, "size")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, histogramErr)
+	assert.NoError(t, summaryErr)
+	assert.Equal(t, histogram, gotHistogram)
+	assert.Equal(t, summary, gotSummary)
+	assert.Equal(t, entity.ErrMetricNotFound, notFoundErr)
+}
+
+func TestStorage_GetHistory(t *testing.T) {
+	// Arrange
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetHistorySize").Return(2)
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	memory, _ := New(cfg, log)
+	ctx := context.Background()
+	from := time.Now()
+	for _, value := range []int64{1, 2, 3} {
+		memory.SaveCounter(ctx, "requests", value)
+	}
+
+	// Act
+	samples, err := memory.GetHistory(ctx, entity.CounterMetric, "requests", from, time.Now())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, samples, 2)
+	assert.Equal(t, float64(3), samples[1].Value)
+}
@@ -0,0 +1,211 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap"
+)
+
+// name of the gauges with durations of maintenance steps
+const maintenanceMetric = "pg_maintenance_seconds"
+
+// rollup - aggregation of a level from the previous one,
+// only complete buckets after the rolled up time of the level are aggregated.
+// LOCALTIMESTAMP is used as created is saved by CURRENT_TIMESTAMP without the time zone
+type rollup struct {
+	level string
+	unit  string
+	// queries get the start of the buckets as $1 and the end as $2
+	queries []string
+}
+
+var rollups = []rollup{
+	{
+		level: "1m",
+		unit:  "minute",
+		queries: []string{`
+			INSERT INTO gauge_1m (name, bucket, avg, min, max, last, count)
+			SELECT name, date_trunc('minute', created) AS bucket, avg(value), min(value), max(value),
+				(array_agg(value ORDER BY id DESC))[1], count(*)
+			FROM gauge
+			WHERE created >= $1 AND created < $2
+			GROUP BY name, bucket
+			ON CONFLICT (name, bucket) DO UPDATE SET
+				avg = (gauge_1m.avg * gauge_1m.count + EXCLUDED.avg * EXCLUDED.count) / (gauge_1m.count + EXCLUDED.count),
+				min = LEAST(gauge_1m.min, EXCLUDED.min),
+				max = GREATEST(gauge_1m.max, EXCLUDED.max),
+				last = EXCLUDED.last,
+				count = gauge_1m.count + EXCLUDED.count;
+		`, `
+			INSERT INTO counter_1m (name, bucket, min, max, last, count)
+			SELECT name, date_trunc('minute', created) AS bucket, min(delta), max(delta),
+				(array_agg(delta ORDER BY id DESC))[1], count(*)
+			FROM counter
+			WHERE created >= $1 AND created < $2
+			GROUP BY name, bucket
+			ON CONFLICT (name, bucket) DO UPDATE SET
+				min = LEAST(counter_1m.min, EXCLUDED.min),
+				max = GREATEST(counter_1m.max, EXCLUDED.max),
+				last = EXCLUDED.last,
+				count = counter_1m.count + EXCLUDED.count;
+		`},
+	},
+	{
+		level: "1h",
+		unit:  "hour",
+		queries: []string{`
+			INSERT INTO gauge_1h (name, bucket, avg, min, max, last, count)
+			SELECT name, date_trunc('hour', bucket) AS hour, sum(avg * count) / sum(count), min(min), max(max),
+				(array_agg(last ORDER BY bucket DESC))[1], sum(count)
+			FROM gauge_1m
+			WHERE bucket >= $1 AND bucket < $2
+			GROUP BY name, hour
+			ON CONFLICT (name, bucket) DO UPDATE SET
+				avg = (gauge_1h.avg * gauge_1h.count + EXCLUDED.avg * EXCLUDED.count) / (gauge_1h.count + EXCLUDED.count),
+				min = LEAST(gauge_1h.min, EXCLUDED.min),
+				max = GREATEST(gauge_1h.max, EXCLUDED.max),
+				last = EXCLUDED.last,
+				count = gauge_1h.count + EXCLUDED.count;
+		`, `
+			INSERT INTO counter_1h (name, bucket, min, max, last, count)
+			SELECT name, date_trunc('hour', bucket) AS hour, min(min), max(max),
+				(array_agg(last ORDER BY bucket DESC))[1], sum(count)
+			FROM counter_1m
+			WHERE bucket >= $1 AND bucket < $2
+			GROUP BY name, hour
+			ON CONFLICT (name, bucket) DO UPDATE SET
+				min = LEAST(counter_1h.min, EXCLUDED.min),
+				max = GREATEST(counter_1h.max, EXCLUDED.max),
+				last = EXCLUDED.last,
+				count = counter_1h.count + EXCLUDED.count;
+		`},
+	},
+}
+
+// retention queries of raw rows, 1-minute and 1-hour rollups get the retention in seconds as $1.
+// Expired daily partitions of raw rows are dropped, so only the default partition is cleaned,
+// the latest raw row of every name is kept for the history before a range
+var retentionQueries = [3][]string{
+	{`
+		DELETE FROM gauge_default WHERE created < LOCALTIMESTAMP - make_interval(secs => $1)
+		AND EXISTS (SELECT 1 FROM gauge newer WHERE newer.name = gauge_default.name AND newer.id > gauge_default.id);
+	`, `
+		DELETE FROM counter_default WHERE created < LOCALTIMESTAMP - make_interval(secs => $1)
+		AND EXISTS (SELECT 1 FROM counter newer WHERE newer.name = counter_default.name AND newer.id > counter_default.id);
+	`},
+	{
+		"DELETE FROM gauge_1m WHERE bucket < LOCALTIMESTAMP - make_interval(secs => $1);",
+		"DELETE FROM counter_1m WHERE bucket < LOCALTIMESTAMP - make_interval(secs => $1);",
+	},
+	{
+		"DELETE FROM gauge_1h WHERE bucket < LOCALTIMESTAMP - make_interval(secs => $1);",
+		"DELETE FROM counter_1h WHERE bucket < LOCALTIMESTAMP - make_interval(secs => $1);",
+	},
+}
+
+// RunMaintenance creates partitions ahead, updates rollups and deletes rows older than the retention until ctx is done,
+// durations of the steps are saved as the gauges pg_maintenance_seconds{step="..."}.
+// Statistics of the pool are saved before the maintenance takes its connections
+func (s *Storage) RunMaintenance(ctx context.Context) {
+	ticker := time.NewTicker(s.maintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SaveAllData(ctx, poolMetrics(s.pool.Stat())); err != nil {
+				s.log.Error("postgresql pool metrics", zap.Error(err))
+			}
+
+			durations, err := s.maintain(ctx)
+			if err != nil {
+				s.log.Error("postgresql maintenance", zap.Error(err))
+			}
+			for step, duration := range durations {
+				name := entity.SeriesID(maintenanceMetric, map[string]string{"step": step})
+				if err := s.SaveGauge(ctx, name, duration.Seconds()); err != nil {
+					s.log.Error("postgresql maintenance metrics", zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+// maintain rolls up every level before the retention deletes the rows of the previous level
+func (s *Storage) maintain(ctx context.Context) (map[string]time.Duration, error) {
+	durations := make(map[string]time.Duration)
+	start := time.Now()
+
+	if err := s.createPartitions(ctx); err != nil {
+		return durations, err
+	}
+	durations["partitions"] = time.Since(start)
+
+	for _, r := range rollups {
+		stepStart := time.Now()
+		if err := s.rollUp(ctx, r); err != nil {
+			return durations, err
+		}
+		durations["rollup_"+r.level] = time.Since(stepStart)
+	}
+
+	stepStart := time.Now()
+	if s.retention[0] > 0 {
+		if err := s.dropExpiredPartitions(ctx, s.retention[0]); err != nil {
+			return durations, err
+		}
+	}
+	for level, queries := range retentionQueries {
+		if s.retention[level] <= 0 {
+			continue
+		}
+		for _, query := range queries {
+			if _, err := s.pool.Exec(ctx, query, s.retention[level].Seconds()); err != nil {
+				return durations, err
+			}
+		}
+	}
+	durations["retention"] = time.Since(stepStart)
+	durations["total"] = time.Since(start)
+
+	return durations, nil
+}
+
+// rollUp aggregates buckets from the rolled up time of the level to the start of the current bucket
+func (s *Storage) rollUp(ctx context.Context, r rollup) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var from, to time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE((SELECT rolled_up FROM rollup_state WHERE level = $1), 'epoch'::TIMESTAMP),
+			date_trunc($2, LOCALTIMESTAMP);
+	`, r.level, r.unit).Scan(&from, &to)
+	if err != nil {
+		return err
+	}
+	if !to.After(from) {
+		return nil
+	}
+
+	for _, query := range r.queries {
+		if _, err := tx.Exec(ctx, query, from, to); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO rollup_state (level, rolled_up) VALUES ($1, $2)
+		ON CONFLICT (level) DO UPDATE SET rolled_up = EXCLUDED.rolled_up;
+	`, r.level, to)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -2,13 +2,125 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
 
 // Cfg is an autogenerated mock type for the cfg type
 type Cfg struct {
 	mock.Mock
 }
 
+// GetDBHealthCheckPeriod provides a mock function with given fields:
+func (_m *Cfg) GetDBHealthCheckPeriod() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDBHealthCheckPeriod")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// GetDBMaxConnIdleTime provides a mock function with given fields:
+func (_m *Cfg) GetDBMaxConnIdleTime() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDBMaxConnIdleTime")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// GetDBMaxConnLifetime provides a mock function with given fields:
+func (_m *Cfg) GetDBMaxConnLifetime() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDBMaxConnLifetime")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// GetDBMaxConns provides a mock function with given fields:
+func (_m *Cfg) GetDBMaxConns() int32 {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDBMaxConns")
+	}
+
+	var r0 int32
+	if rf, ok := ret.Get(0).(func() int32); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int32)
+	}
+
+	return r0
+}
+
+// GetDBMinConns provides a mock function with given fields:
+func (_m *Cfg) GetDBMinConns() int32 {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDBMinConns")
+	}
+
+	var r0 int32
+	if rf, ok := ret.Get(0).(func() int32); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int32)
+	}
+
+	return r0
+}
+
+// GetDBStatementCache provides a mock function with given fields:
+func (_m *Cfg) GetDBStatementCache() int {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDBStatementCache")
+	}
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
 // GetDatabaseConnectionString provides a mock function with given fields:
 func (_m *Cfg) GetDatabaseConnectionString() string {
 	ret := _m.Called()
@@ -45,6 +157,24 @@ func (_m *Cfg) GetFileStoragePath() string {
 	return r0
 }
 
+// GetMaintenanceInterval provides a mock function with given fields:
+func (_m *Cfg) GetMaintenanceInterval() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMaintenanceInterval")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
 // GetRestore provides a mock function with given fields:
 func (_m *Cfg) GetRestore() bool {
 	ret := _m.Called()
@@ -63,6 +193,60 @@ func (_m *Cfg) GetRestore() bool {
 	return r0
 }
 
+// GetRetention1h provides a mock function with given fields:
+func (_m *Cfg) GetRetention1h() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRetention1h")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// GetRetention1m provides a mock function with given fields:
+func (_m *Cfg) GetRetention1m() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRetention1m")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// GetRetentionRaw provides a mock function with given fields:
+func (_m *Cfg) GetRetentionRaw() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRetentionRaw")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
 // NewCfg creates a new instance of Cfg. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewCfg(t interface {
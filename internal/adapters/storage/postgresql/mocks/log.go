@@ -13,6 +13,18 @@ type Log struct {
 	mock.Mock
 }
 
+// Error provides a mock function with given fields: msg, fields
+func (_m *Log) Error(msg string, fields ...zapcore.Field) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, msg)
+	_ca = append(_ca, _va...)
+	_m.Called(_ca...)
+}
+
 // Info provides a mock function with given fields: msg, fields
 func (_m *Log) Info(msg string, fields ...zapcore.Field) {
 	_va := make([]interface{}, len(fields))
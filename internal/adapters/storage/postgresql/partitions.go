@@ -0,0 +1,160 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// days of partitions created ahead of today
+	partitionsAhead = 3
+	// suffix of a daily partition: gauge_p20240501
+	partitionLayout = "20060102"
+)
+
+// tables partitioned by the day of created
+var partitionedTables = []string{"gauge", "counter"}
+
+func partitionName(table string, day time.Time) string {
+	return table + "_p" + day.Format(partitionLayout)
+}
+
+// createPartitions creates daily partitions from today to partitionsAhead days ahead,
+// days are counted in the time zone of the session like created
+func (s *Storage) createPartitions(ctx context.Context) error {
+	var today time.Time
+	if err := s.pool.QueryRow(ctx, "SELECT LOCALTIMESTAMP::DATE").Scan(&today); err != nil {
+		return err
+	}
+
+	for _, table := range partitionedTables {
+		for i := 0; i <= partitionsAhead; i++ {
+			if err := s.createPartition(ctx, table, today.AddDate(0, 0, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createPartition attaches a new partition of the day, rows of the day saved to the default partition
+// while the partition was missing are moved to it, otherwise the partition can't be attached
+func (s *Storage) createPartition(ctx context.Context, table string, day time.Time) error {
+	name := partitionName(table, day)
+
+	var exists bool
+	if err := s.pool.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", name).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	from, to := day.Format(time.DateOnly), day.AddDate(0, 0, 1).Format(time.DateOnly)
+	queries := []string{
+		fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)", name, table),
+		fmt.Sprintf(`
+			WITH moved AS (
+				DELETE FROM %[1]s_default WHERE created >= '%[3]s' AND created < '%[4]s' RETURNING *
+			)
+			INSERT INTO %[2]s SELECT * FROM moved
+		`, table, name, from, to),
+		fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')", table, name, from, to),
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(ctx, query); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// dropExpiredPartitions drops daily partitions whose days ended before the retention,
+// the latest row of every name is moved to the default partition first, so the history still has it
+func (s *Storage) dropExpiredPartitions(ctx context.Context, retention time.Duration) error {
+	var cutoff time.Time
+	err := s.pool.QueryRow(ctx, "SELECT LOCALTIMESTAMP - make_interval(secs => $1)", retention.Seconds()).Scan(&cutoff)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range partitionedTables {
+		partitions, err := s.partitions(ctx, table)
+		if err != nil {
+			return err
+		}
+		for _, name := range partitions {
+			day, err := time.Parse(partitionLayout, strings.TrimPrefix(name, table+"_p"))
+			if err != nil {
+				// not a daily partition
+				continue
+			}
+			if day.AddDate(0, 0, 1).After(cutoff) {
+				continue
+			}
+			if err := s.dropPartition(ctx, table, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// partitions returns names of the partitions of the table
+func (s *Storage) partitions(ctx context.Context, table string) ([]string, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT child.relname
+		FROM pg_inherits
+		JOIN pg_class parent ON parent.oid = pg_inherits.inhparent
+		JOIN pg_class child ON child.oid = pg_inherits.inhrelid
+		WHERE parent.relname = $1
+		ORDER BY child.relname;
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partitions []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, name)
+	}
+	return partitions, rows.Err()
+}
+
+func (s *Storage) dropPartition(ctx context.Context, table, name string) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := []string{
+		fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", table, name),
+		// the range of the detached partition isn't covered, so the rows go to the default partition
+		fmt.Sprintf(`
+			INSERT INTO %[1]s
+			SELECT * FROM (SELECT DISTINCT ON (name) * FROM %[2]s ORDER BY name, id DESC) AS latest
+			WHERE NOT EXISTS (SELECT 1 FROM %[1]s newer WHERE newer.name = latest.name AND newer.id > latest.id)
+		`, table, name),
+		fmt.Sprintf("DROP TABLE %s", name),
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(ctx, query); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
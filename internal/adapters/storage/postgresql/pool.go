@@ -0,0 +1,37 @@
+package postgresql
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+)
+
+// poolMetrics returns the statistics of the pool: connections by state as gauges,
+// totals since the start as counters, they are saved as the absolute values
+func poolMetrics(stat *pgxpool.Stat) []entity.Metrics {
+	gauges := map[string]float64{
+		entity.SeriesID("pg_pool_connections", map[string]string{"state": "acquired"}):     float64(stat.AcquiredConns()),
+		entity.SeriesID("pg_pool_connections", map[string]string{"state": "idle"}):         float64(stat.IdleConns()),
+		entity.SeriesID("pg_pool_connections", map[string]string{"state": "constructing"}): float64(stat.ConstructingConns()),
+		"pg_pool_max_connections":          float64(stat.MaxConns()),
+		"pg_pool_acquire_duration_seconds": stat.AcquireDuration().Seconds(),
+	}
+	counters := map[string]int64{
+		"pg_pool_acquires":          stat.AcquireCount(),
+		"pg_pool_empty_acquires":    stat.EmptyAcquireCount(),
+		"pg_pool_canceled_acquires": stat.CanceledAcquireCount(),
+		"pg_pool_new_connections":   stat.NewConnsCount(),
+		entity.SeriesID("pg_pool_destroyed_connections", map[string]string{"reason": "lifetime"}): stat.MaxLifetimeDestroyCount(),
+		entity.SeriesID("pg_pool_destroyed_connections", map[string]string{"reason": "idle"}):     stat.MaxIdleDestroyCount(),
+	}
+
+	metrics := make([]entity.Metrics, 0, len(gauges)+len(counters))
+	for name, value := range gauges {
+		value := value
+		metrics = append(metrics, entity.Metrics{ID: name, MType: entity.GaugeMetric, Value: &value})
+	}
+	for name, delta := range counters {
+		delta := delta
+		metrics = append(metrics, entity.Metrics{ID: name, MType: entity.CounterMetric, Delta: &delta})
+	}
+	return metrics
+}
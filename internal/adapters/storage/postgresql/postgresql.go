@@ -3,28 +3,157 @@ package postgresql
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
-	"github.com/lib/pq"
 	"github.com/pressly/goose"
 	"go.uber.org/zap/zapcore"
 )
 
+// SQLSTATE codes of errors after which a statement is retried
 const (
-	UniqueViolation = "unique_violation"
+	UniqueViolation      = "23505"
+	SerializationFailure = "40001"
+	DeadlockDetected     = "40P01"
+	// class of connection exceptions
+	connectionException = "08"
 )
 
+// queries write the history row and the latest value in one statement,
+// they get the name as $1 and the value as $2
+const (
+	saveGaugeQuery = `
+		WITH latest AS (
+			INSERT INTO metric_latest (type, name, value) VALUES ('gauge', $1, $2)
+			ON CONFLICT (type, name) DO UPDATE SET value = EXCLUDED.value, updated = CURRENT_TIMESTAMP
+		)
+		INSERT INTO gauge (name, value) VALUES ($1, $2);
+	`
+	saveCounterQuery = `
+		WITH latest AS (
+			INSERT INTO metric_latest (type, name, delta) VALUES ('counter', $1, $2)
+			ON CONFLICT (type, name) DO UPDATE SET delta = EXCLUDED.delta, updated = CURRENT_TIMESTAMP
+		)
+		INSERT INTO counter (name, delta) VALUES ($1, $2);
+	`
+	// the conflicting row is locked until the end of the statement, so concurrent increments are serialized
+	incrementCounterQuery = `
+		WITH latest AS (
+			INSERT INTO metric_latest (type, name, delta) VALUES ('counter', $1, $2)
+			ON CONFLICT (type, name) DO UPDATE SET delta = metric_latest.delta + EXCLUDED.delta, updated = CURRENT_TIMESTAMP
+			RETURNING delta
+		)
+		INSERT INTO counter (name, delta) SELECT $1, delta FROM latest
+		RETURNING delta;
+	`
+	addGaugeQuery = `
+		WITH latest AS (
+			INSERT INTO metric_latest (type, name, value) VALUES ('gauge', $1, $2)
+			ON CONFLICT (type, name) DO UPDATE SET value = metric_latest.value + EXCLUDED.value, updated = CURRENT_TIMESTAMP
+			RETURNING value
+		)
+		INSERT INTO gauge (name, value) SELECT $1, value FROM latest
+		RETURNING value;
+	`
+	saveHistogramQuery = `
+		WITH latest AS (
+			INSERT INTO metric_latest (type, name, data) VALUES ('histogram', $1, $2)
+			ON CONFLICT (type, name) DO UPDATE SET data = EXCLUDED.data, updated = CURRENT_TIMESTAMP
+		)
+		INSERT INTO histogram (name, value) VALUES ($1, $2);
+	`
+	saveSummaryQuery = `
+		WITH latest AS (
+			INSERT INTO metric_latest (type, name, data) VALUES ('summary', $1, $2)
+			ON CONFLICT (type, name) DO UPDATE SET data = EXCLUDED.data, updated = CURRENT_TIMESTAMP
+		)
+		INSERT INTO summary (name, value) VALUES ($1, $2);
+	`
+	// buckets are added by their positions like in entity.HistogramValue.Merge,
+	// the added histogram replaces the latest one if the bounds or the number of buckets differ
+	addHistogramQuery = `
+		WITH latest AS (
+			INSERT INTO metric_latest (type, name, data) VALUES ('histogram', $1, $2)
+			ON CONFLICT (type, name) DO UPDATE SET data = CASE
+				WHEN jsonb_typeof(metric_latest.data->'counts') IS DISTINCT FROM 'array'
+					OR metric_latest.data->'bounds' IS DISTINCT FROM EXCLUDED.data->'bounds'
+				THEN EXCLUDED.data
+				WHEN jsonb_array_length(metric_latest.data->'counts') <> jsonb_array_length(EXCLUDED.data->'counts')
+				THEN EXCLUDED.data
+				ELSE jsonb_build_object(
+					'bounds', EXCLUDED.data->'bounds',
+					'counts', (
+						SELECT jsonb_agg(latest_counts.n::NUMERIC + added_counts.n::NUMERIC ORDER BY i)
+						FROM jsonb_array_elements_text(metric_latest.data->'counts') WITH ORDINALITY AS latest_counts(n, i)
+						JOIN jsonb_array_elements_text(EXCLUDED.data->'counts') WITH ORDINALITY AS added_counts(n, i) USING (i)
+					),
+					'sum', (metric_latest.data->>'sum')::DOUBLE PRECISION + (EXCLUDED.data->>'sum')::DOUBLE PRECISION,
+					'count', (metric_latest.data->>'count')::NUMERIC + (EXCLUDED.data->>'count')::NUMERIC
+				)
+			END, updated = CURRENT_TIMESTAMP
+			RETURNING data
+		)
+		INSERT INTO histogram (name, value) SELECT $1, data FROM latest
+		RETURNING value;
+	`
+	// quantiles can't be added, so the added ones replace the latest ones like in entity.SummaryValue.Merge
+	addSummaryQuery = `
+		WITH latest AS (
+			INSERT INTO metric_latest (type, name, data) VALUES ('summary', $1, $2)
+			ON CONFLICT (type, name) DO UPDATE SET data = jsonb_build_object(
+				'quantiles', EXCLUDED.data->'quantiles',
+				'sum', (metric_latest.data->>'sum')::DOUBLE PRECISION + (EXCLUDED.data->>'sum')::DOUBLE PRECISION,
+				'count', (metric_latest.data->>'count')::NUMERIC + (EXCLUDED.data->>'count')::NUMERIC
+			), updated = CURRENT_TIMESTAMP
+			RETURNING data
+		)
+		INSERT INTO summary (name, value) SELECT $1, data FROM latest
+		RETURNING value;
+	`
+)
+
+// tables with samples of gauges and counters: raw rows, 1-minute and 1-hour rollups
+type historySource struct {
+	table string
+	time  string
+	value string
+}
+
+var historySources = map[string][]historySource{
+	entity.GaugeMetric: {
+		{table: "gauge", time: "created", value: "value"},
+		{table: "gauge_1m", time: "bucket", value: "avg"},
+		{table: "gauge_1h", time: "bucket", value: "avg"},
+	},
+	entity.CounterMetric: {
+		{table: "counter", time: "created", value: "delta::DOUBLE PRECISION"},
+		{table: "counter_1m", time: "bucket", value: "last::DOUBLE PRECISION"},
+		{table: "counter_1h", time: "bucket", value: "last::DOUBLE PRECISION"},
+	},
+}
+
 type Storage struct {
-	db *sql.DB
+	pool *pgxpool.Pool
+	log  log
+
+	maintenanceInterval time.Duration
+	// retention of raw rows, 1-minute and 1-hour rollups, 0 keeps rows forever
+	retention [3]time.Duration
 }
 
 //go:generate mockery --name log --exported
 type log interface {
 	Info(msg string, fields ...zapcore.Field)
+	Error(msg string, fields ...zapcore.Field)
 }
 
 //go:generate mockery --name cfg --exported
@@ -32,60 +161,135 @@ type cfg interface {
 	GetFileStoragePath() string
 	GetRestore() bool
 	GetDatabaseConnectionString() string
+	GetMaintenanceInterval() time.Duration
+	GetRetentionRaw() time.Duration
+	GetRetention1m() time.Duration
+	GetRetention1h() time.Duration
+	GetDBMaxConns() int32
+	GetDBMinConns() int32
+	GetDBMaxConnLifetime() time.Duration
+	GetDBMaxConnIdleTime() time.Duration
+	GetDBHealthCheckPeriod() time.Duration
+	GetDBStatementCache() int
 }
 
 func New(config cfg, log log) (*Storage, error) {
 	log.Info("Storage is database")
 
-	db, err := sql.Open("pgx", config.GetDatabaseConnectionString())
+	poolConfig, err := pgxpool.ParseConfig(config.GetDatabaseConnectionString())
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.MaxConns = config.GetDBMaxConns()
+	poolConfig.MinConns = config.GetDBMinConns()
+	poolConfig.MaxConnLifetime = config.GetDBMaxConnLifetime()
+	poolConfig.MaxConnIdleTime = config.GetDBMaxConnIdleTime()
+	poolConfig.HealthCheckPeriod = config.GetDBHealthCheckPeriod()
+	// statements are prepared once per connection and cached,
+	// without the cache every query is described before the execution
+	poolConfig.ConnConfig.StatementCacheCapacity = config.GetDBStatementCache()
+	if config.GetDBStatementCache() == 0 {
+		poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	storage := &Storage{
-		db: db,
+		pool:                pool,
+		log:                 log,
+		maintenanceInterval: config.GetMaintenanceInterval(),
+		retention:           [3]time.Duration{config.GetRetentionRaw(), config.GetRetention1m(), config.GetRetention1h()},
 	}
 
 	if err := storage.runMigrations(); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	// partitions of today and ahead are needed before the first maintenance
+	if err := storage.createPartitions(context.Background()); err != nil {
+		pool.Close()
 		return nil, err
 	}
 
 	return storage, nil
 }
 
+// runMigrations opens database/sql for goose with the config of the pool
 func (s *Storage) runMigrations() error {
-	if err := goose.Run("up", s.db, "deployments/db/migrations"); err != nil {
+	db := stdlib.OpenDB(*s.pool.Config().ConnConfig)
+	defer db.Close()
+
+	if err := goose.Run("up", db, "deployments/db/migrations"); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// SaveAllData writes the metrics in one transaction,
+// the statements are sent as a pgx batch, so the whole batch takes one round trip.
+// Rows of metric_latest are locked in the order of (type, name), so concurrent batches with the same names
+// can't deadlock, the transaction is retried like a single statement
 func (s *Storage) SaveAllData(ctx context.Context, metrics []entity.Metrics) error {
-	var err error
+	metrics = lockOrder(metrics)
+
+	return retry(func() error {
+		batch, err := newBatch(metrics)
+		if err != nil || batch.Len() == 0 {
+			return err
+		}
+		// a batch is sent only once, so every attempt queues the statements again
+		return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+			return tx.SendBatch(ctx, batch).Close()
+		})
+	})
+}
 
+// lockOrder returns a copy of the metrics sorted by the type and the name
+func lockOrder(metrics []entity.Metrics) []entity.Metrics {
+	sorted := make([]entity.Metrics, len(metrics))
+	copy(sorted, metrics)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].MType != sorted[j].MType {
+			return sorted[i].MType < sorted[j].MType
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+func newBatch(metrics []entity.Metrics) (*pgx.Batch, error) {
+	batch := &pgx.Batch{}
 	for _, v := range metrics {
 		switch v.MType {
-		case "gauge":
-			query := "INSERT INTO gauge (name, value) VALUES ($1,$2)"
-			err = s.retryableExec(ctx, query, v.ID, v.Value)
-		case "counter":
-			query := "INSERT INTO counter (name, delta) VALUES ($1,$2)"
-			err = s.retryableExec(ctx, query, v.ID, v.Delta)
+		case entity.GaugeMetric:
+			batch.Queue(saveGaugeQuery, v.ID, v.Value)
+		case entity.CounterMetric:
+			batch.Queue(saveCounterQuery, v.ID, v.Delta)
+		case entity.HistogramMetric:
+			value, err := marshalHistogram(*v.Histogram)
+			if err != nil {
+				return nil, err
+			}
+			batch.Queue(addHistogramQuery, v.ID, value)
+		case entity.SummaryMetric:
+			value, err := json.Marshal(v.Summary)
+			if err != nil {
+				return nil, err
+			}
+			batch.Queue(addSummaryQuery, v.ID, value)
 		default:
-			return entity.ErrInputVarIsWrongType
-		}
-		if err != nil {
-			return err
+			return nil, entity.ErrInputVarIsWrongType
 		}
 	}
-
-	return nil
+	return batch, nil
 }
 
 func (s *Storage) SaveGauge(ctx context.Context, gaugeName string, gaugeValue float64) error {
-	query := "INSERT INTO gauge (name, value) VALUES ($1, $2)"
-	if err := s.retryableExec(ctx, query, gaugeName, gaugeValue); err != nil {
+	if err := s.retryableExec(ctx, saveGaugeQuery, gaugeName, gaugeValue); err != nil {
 		return err
 	}
 	return nil
@@ -93,9 +297,9 @@ func (s *Storage) SaveGauge(ctx context.Context, gaugeName string, gaugeValue fl
 
 func (s *Storage) GetGauge(ctx context.Context, gaugeName string) (float64, error) {
 	var gaugeValue float64
-	err := s.db.QueryRowContext(ctx, "SELECT value FROM gauge WHERE name = $1 ORDER BY id DESC", gaugeName).Scan(&gaugeValue)
+	err := s.pool.QueryRow(ctx, "SELECT value FROM metric_latest WHERE type = 'gauge' AND name = $1", gaugeName).Scan(&gaugeValue)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return 0, entity.ErrMetricNotFound
 		}
 		return 0, err
@@ -103,121 +307,373 @@ func (s *Storage) GetGauge(ctx context.Context, gaugeName string) (float64, erro
 	return gaugeValue, nil
 }
 
+// AddGauge adds the value to the latest value and saves the new value to the history
+func (s *Storage) AddGauge(ctx context.Context, gaugeName string, value float64) (float64, error) {
+	var gaugeValue float64
+	err := retry(func() error {
+		return s.pool.QueryRow(ctx, addGaugeQuery, gaugeName, value).Scan(&gaugeValue)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return gaugeValue, nil
+}
+
 func (s *Storage) SaveCounter(ctx context.Context, counterName string, counterValue int64) error {
-	query := "INSERT INTO counter (name, delta) VALUES ($1, $2)"
-	if err := s.retryableExec(ctx, query, counterName, counterValue); err != nil {
+	if err := s.retryableExec(ctx, saveCounterQuery, counterName, counterValue); err != nil {
 		return err
 	}
 	return nil
 }
 
+// IncrementCounter adds the delta to the latest value and saves the new value to the history
+func (s *Storage) IncrementCounter(ctx context.Context, counterName string, delta int64) (int64, error) {
+	var counterValue int64
+	err := retry(func() error {
+		return s.pool.QueryRow(ctx, incrementCounterQuery, counterName, delta).Scan(&counterValue)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return counterValue, nil
+}
+
 func (s *Storage) GetCounter(ctx context.Context, counterName string) (int64, error) {
 	var counterValue int64
-	err := s.db.QueryRowContext(ctx, "SELECT delta FROM counter WHERE name = $1 ORDER BY id DESC", counterName).Scan(&counterValue)
+	err := s.pool.QueryRow(ctx, "SELECT delta FROM metric_latest WHERE type = 'counter' AND name = $1", counterName).Scan(&counterValue)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return 0, entity.ErrMetricNotFound
-		} else {
-			return 0, err
 		}
+		return 0, err
 	}
 	return counterValue, nil
 }
 
+func (s *Storage) SaveHistogram(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) error {
+	value, err := marshalHistogram(histogramValue)
+	if err != nil {
+		return err
+	}
+
+	return s.retryableExec(ctx, saveHistogramQuery, histogramName, value)
+}
+
+// AddHistogram merges the histogram into the latest one and saves the merged histogram to the history
+func (s *Storage) AddHistogram(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) (entity.HistogramValue, error) {
+	value, err := marshalHistogram(histogramValue)
+	if err != nil {
+		return entity.HistogramValue{}, err
+	}
+
+	var merged []byte
+	err = retry(func() error {
+		return s.pool.QueryRow(ctx, addHistogramQuery, histogramName, value).Scan(&merged)
+	})
+	if err != nil {
+		return entity.HistogramValue{}, err
+	}
+	var mergedValue entity.HistogramValue
+	return mergedValue, json.Unmarshal(merged, &mergedValue)
+}
+
+func (s *Storage) GetHistogram(ctx context.Context, histogramName string) (entity.HistogramValue, error) {
+	var histogramValue entity.HistogramValue
+	err := s.getJSON(ctx, "SELECT data FROM metric_latest WHERE type = 'histogram' AND name = $1", histogramName, &histogramValue)
+	return histogramValue, err
+}
+
+// marshalHistogram encodes the histogram with empty bounds as an array, so the bounds are compared by the upsert
+func marshalHistogram(histogramValue entity.HistogramValue) ([]byte, error) {
+	if histogramValue.Bounds == nil {
+		histogramValue.Bounds = []float64{}
+	}
+	return json.Marshal(histogramValue)
+}
+
+func (s *Storage) SaveSummary(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) error {
+	value, err := json.Marshal(summaryValue)
+	if err != nil {
+		return err
+	}
+
+	return s.retryableExec(ctx, saveSummaryQuery, summaryName, value)
+}
+
+// AddSummary adds the sum and the count to the latest summary and saves the merged summary to the history
+func (s *Storage) AddSummary(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) (entity.SummaryValue, error) {
+	value, err := json.Marshal(summaryValue)
+	if err != nil {
+		return entity.SummaryValue{}, err
+	}
+
+	var merged []byte
+	err = retry(func() error {
+		return s.pool.QueryRow(ctx, addSummaryQuery, summaryName, value).Scan(&merged)
+	})
+	if err != nil {
+		return entity.SummaryValue{}, err
+	}
+	var mergedValue entity.SummaryValue
+	return mergedValue, json.Unmarshal(merged, &mergedValue)
+}
+
+func (s *Storage) GetSummary(ctx context.Context, summaryName string) (entity.SummaryValue, error) {
+	var summaryValue entity.SummaryValue
+	err := s.getJSON(ctx, "SELECT data FROM metric_latest WHERE type = 'summary' AND name = $1", summaryName, &summaryValue)
+	return summaryValue, err
+}
+
+// getJSON decodes the JSONB value of the metric
+func (s *Storage) getJSON(ctx context.Context, query, name string, v any) error {
+	var value []byte
+	if err := s.pool.QueryRow(ctx, query, name).Scan(&value); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ErrMetricNotFound
+		}
+		return err
+	}
+	return json.Unmarshal(value, v)
+}
+
+// GetHistory returns the saved rows between from and to and the latest row before from.
+// Rows are read from the most detailed table that still keeps from: raw rows, 1-minute or 1-hour rollups,
+// rollups have the average of a gauge and the last value of a counter.
+// Times are saved in the time zone of the session, so they are converted to compare with the arguments
+func (s *Storage) GetHistory(ctx context.Context, metricType, metricName string, from, to time.Time) ([]entity.Sample, error) {
+	sources, ok := historySources[metricType]
+	if !ok {
+		return nil, entity.ErrInputVarIsWrongType
+	}
+	level := 0
+	for level < len(sources)-1 && s.retention[level] > 0 && from.Before(time.Now().Add(-s.retention[level])) {
+		level++
+	}
+	source := sources[level]
+
+	query := fmt.Sprintf(`
+		SELECT %[2]s AT TIME ZONE current_setting('TimeZone'), %[3]s FROM (
+			(SELECT %[2]s, %[3]s FROM %[1]s
+			WHERE name = $1 AND %[2]s < ($2::TIMESTAMPTZ AT TIME ZONE current_setting('TimeZone'))
+			ORDER BY %[2]s DESC LIMIT 1)
+			UNION ALL
+			(SELECT %[2]s, %[3]s FROM %[1]s
+			WHERE name = $1 AND %[2]s >= ($2::TIMESTAMPTZ AT TIME ZONE current_setting('TimeZone'))
+			AND %[2]s <= ($3::TIMESTAMPTZ AT TIME ZONE current_setting('TimeZone')))
+		) AS samples
+		ORDER BY %[2]s;
+	`, source.table, source.time, source.value)
+	rows, err := s.pool.Query(ctx, query, metricName, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []entity.Sample
+	for rows.Next() {
+		var sample entity.Sample
+		if err := rows.Scan(&sample.Time, &sample.Value); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
 func (s *Storage) GetAllData(ctx context.Context) (entity.MetricsType, error) {
 	metrics := entity.MetricsType{
-		Gauge:   make(map[string]float64),
-		Counter: make(map[string]int64),
+		Gauge:     make(entity.GaugeType),
+		Counter:   make(entity.CounterType),
+		Histogram: make(entity.HistogramType),
+		Summary:   make(entity.SummaryType),
 	}
 
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT DISTINCT ON (name) name, value
-		FROM gauge
-		ORDER BY name, id DESC;
-	`)
+	rows, err := s.pool.Query(ctx, "SELECT type, name, value, delta, data FROM metric_latest")
 	if err != nil {
 		return metrics, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var name string
-		var value float64
-		if err := rows.Scan(&name, &value); err != nil {
+		var metricType, name string
+		var value *float64
+		var delta *int64
+		var data []byte
+		if err := rows.Scan(&metricType, &name, &value, &delta, &data); err != nil {
 			return metrics, err
 		}
-		if err := rows.Err(); err != nil {
-			return metrics, err
+		switch metricType {
+		case entity.GaugeMetric:
+			if value != nil {
+				metrics.Gauge[name] = *value
+			}
+		case entity.CounterMetric:
+			if delta != nil {
+				metrics.Counter[name] = *delta
+			}
+		case entity.HistogramMetric:
+			var histogramValue entity.HistogramValue
+			if err := json.Unmarshal(data, &histogramValue); err != nil {
+				return metrics, err
+			}
+			metrics.Histogram[name] = histogramValue
+		case entity.SummaryMetric:
+			var summaryValue entity.SummaryValue
+			if err := json.Unmarshal(data, &summaryValue); err != nil {
+				return metrics, err
+			}
+			metrics.Summary[name] = summaryValue
 		}
-		metrics.Gauge[name] = value
 	}
+	if err := rows.Err(); err != nil {
+		return metrics, err
+	}
+
+	return metrics, nil
+}
 
-	rows, err = s.db.QueryContext(ctx, `
-		SELECT DISTINCT ON (name) name, delta
-		FROM counter
-		ORDER BY name, id DESC;
-	`)
+func (s *Storage) SaveHostInfo(ctx context.Context, hostInfo entity.HostInfo) error {
+	info, err := json.Marshal(hostInfo)
 	if err != nil {
-		return metrics, err
+		return err
+	}
+
+	query := `
+		INSERT INTO host_info (hostname, updated, info) VALUES ($1, $2, $3)
+		ON CONFLICT (hostname) DO UPDATE SET updated = EXCLUDED.updated, info = EXCLUDED.info
+	`
+	return s.retryableExec(ctx, query, hostInfo.Hostname, hostInfo.Updated, info)
+}
+
+func (s *Storage) GetAllHostInfo(ctx context.Context) ([]entity.HostInfo, error) {
+	var hosts []entity.HostInfo
+
+	rows, err := s.pool.Query(ctx, "SELECT info FROM host_info")
+	if err != nil {
+		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var name string
-		var delta int64
-		if err := rows.Scan(&name, &delta); err != nil {
-			return metrics, err
+		var info []byte
+		if err := rows.Scan(&info); err != nil {
+			return nil, err
 		}
-		if err := rows.Err(); err != nil {
-			return metrics, err
+		var hostInfo entity.HostInfo
+		if err := json.Unmarshal(info, &hostInfo); err != nil {
+			return nil, err
 		}
-		metrics.Counter[name] = delta
+		hosts = append(hosts, hostInfo)
 	}
 
-	return metrics, nil
+	return hosts, rows.Err()
+}
+
+// SaveAgent keeps the first seen time, the config and adds the received metrics
+func (s *Storage) SaveAgent(ctx context.Context, agent entity.Agent) error {
+	query := `
+		INSERT INTO agents (id, agent_group, address, version, report_interval, metrics, first_seen, last_seen)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			agent_group = EXCLUDED.agent_group,
+			address = EXCLUDED.address,
+			version = EXCLUDED.version,
+			report_interval = EXCLUDED.report_interval,
+			metrics = agents.metrics + EXCLUDED.metrics,
+			last_seen = EXCLUDED.last_seen
+	`
+	return s.retryableExec(ctx, query, agent.ID, agent.Group, agent.Address, agent.Version, agent.ReportInterval, agent.Metrics, agent.FirstSeen, agent.LastSeen)
+}
+
+// SaveAgentConfig saves the effective config of a registered agent
+func (s *Storage) SaveAgentConfig(ctx context.Context, agentID string, agentConfig entity.AgentConfig) error {
+	config, err := json.Marshal(agentConfig)
+	if err != nil {
+		return err
+	}
+
+	return s.retryableExec(ctx, "UPDATE agents SET config = $2 WHERE id = $1", agentID, config)
+}
+
+func (s *Storage) GetAllAgents(ctx context.Context) ([]entity.Agent, error) {
+	var agents []entity.Agent
+
+	rows, err := s.pool.Query(ctx, "SELECT id, agent_group, address, version, report_interval, metrics, first_seen, last_seen, config FROM agents")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var agent entity.Agent
+		var config []byte
+		if err := rows.Scan(&agent.ID, &agent.Group, &agent.Address, &agent.Version, &agent.ReportInterval, &agent.Metrics, &agent.FirstSeen, &agent.LastSeen, &config); err != nil {
+			return nil, err
+		}
+		if config != nil {
+			agent.Config = new(entity.AgentConfig)
+			if err := json.Unmarshal(config, agent.Config); err != nil {
+				return nil, err
+			}
+		}
+		agents = append(agents, agent)
+	}
+
+	return agents, rows.Err()
 }
 
 func (s *Storage) Ping(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	return s.db.PingContext(ctx)
+	return s.pool.Ping(ctx)
 }
 
-func (s *Storage) retryableExec(ctx context.Context, query string, args ...any) error {
-	const maxRetries = 3
-	var retryDelays = []time.Duration{1 * time.Second, 3 * time.Second, 5 * time.Second}
-	var pqError *pq.Error
+// Close closes all connections of the pool
+func (s *Storage) Close() {
+	s.pool.Close()
+}
 
-	tx, err := s.db.Begin()
-	if err != nil {
+func (s *Storage) retryableExec(ctx context.Context, query string, args ...any) error {
+	// a single statement is atomic, so it doesn't need a transaction
+	return retry(func() error {
+		_, err := s.pool.Exec(ctx, query, args...)
 		return err
-	}
-	defer tx.Rollback()
+	})
+}
 
-	stmt, err := tx.PrepareContext(ctx, query)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
+// retry runs fn again while it fails with a retryable error
+func retry(fn func() error) error {
+	var retryDelays = []time.Duration{1 * time.Second, 3 * time.Second, 5 * time.Second}
 
-	for i := 0; i <= maxRetries; i++ {
+	var err error
+	for i := 0; i <= len(retryDelays); i++ {
 		if i > 0 {
 			// Waiting before trying again
 			time.Sleep(retryDelays[i-1])
 		}
 
-		if _, err = stmt.ExecContext(ctx, args...); err != nil {
-			// Checking for a unique violation (UniqueViolation)
-			if errors.As(err, &pqError) {
-				if pqError.Code.Name() == UniqueViolation {
-					continue
-				}
-			}
-
+		if err = fn(); err == nil || !isRetryable(err) {
 			return err
 		}
-		return tx.Commit() // Successful execution
 	}
-	return errors.New("max retries exceeded")
+	return fmt.Errorf("max retries exceeded: %w", err)
+}
+
+// isRetryable reports whether the statement may succeed if it is executed again:
+// the error happened before the statement was sent, it is a conflict with a concurrent transaction or a connection exception
+func isRetryable(err error) bool {
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var pgError *pgconn.PgError
+	if !errors.As(err, &pgError) {
+		return false
+	}
+	switch pgError.Code {
+	case UniqueViolation, SerializationFailure, DeadlockDetected:
+		return true
+	}
+	return strings.HasPrefix(pgError.Code, connectionException)
 }
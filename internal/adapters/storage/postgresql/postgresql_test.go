@@ -0,0 +1,204 @@
+// Storage with postgresql
+
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// agentBatch returns a batch like a report of the agent: gauges of runtime and system metrics and PollCount
+func agentBatch() []entity.Metrics {
+	metrics := make([]entity.Metrics, 0, 30)
+	for i := 0; i < 29; i++ {
+		value := float64(i) + 0.5
+		metrics = append(metrics, entity.Metrics{ID: fmt.Sprintf("bench_gauge_%d", i), MType: entity.GaugeMetric, Value: &value})
+	}
+	delta := int64(1)
+	metrics = append(metrics, entity.Metrics{ID: "bench_PollCount", MType: entity.CounterMetric, Delta: &delta})
+
+	return metrics
+}
+
+// newTestStorage connects to the database in BENCH_DATABASE_DSN, the database must be migrated
+func newTestStorage(tb testing.TB) *Storage {
+	dsn := os.Getenv("BENCH_DATABASE_DSN")
+	if dsn == "" {
+		tb.Skip("BENCH_DATABASE_DSN is not set")
+	}
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(pool.Close)
+
+	return &Storage{pool: pool}
+}
+
+func reversed(metrics []entity.Metrics) []entity.Metrics {
+	reversed := make([]entity.Metrics, 0, len(metrics))
+	for i := len(metrics) - 1; i >= 0; i-- {
+		reversed = append(reversed, metrics[i])
+	}
+	return reversed
+}
+
+func Test_lockOrder(t *testing.T) {
+	// Arrange
+	batch := agentBatch()
+
+	// Act
+	got := lockOrder(batch)
+	gotReversed := lockOrder(reversed(batch))
+
+	// Assert
+	assert.Equal(t, got, gotReversed)
+	assert.Equal(t, entity.CounterMetric, got[0].MType)
+	assert.Equal(t, "bench_gauge_0", got[1].ID)
+	assert.Equal(t, "bench_PollCount", batch[len(batch)-1].ID, "the batch of the caller is not sorted")
+}
+
+func Test_isRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "deadlock",
+			err:  fmt.Errorf("batch: %w", &pgconn.PgError{Code: DeadlockDetected}),
+			want: true,
+		},
+		{
+			name: "connection exception",
+			err:  &pgconn.PgError{Code: "08006"},
+			want: true,
+		},
+		{
+			name: "not null violation",
+			err:  &pgconn.PgError{Code: "23502"},
+		},
+		{
+			name: "not a PostgreSQL error",
+			err:  errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := isRetryable(tt.err)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestStorage_SaveAllData_concurrent saves overlapping batches in reversed orders at once,
+// it needs a migrated database in BENCH_DATABASE_DSN
+func TestStorage_SaveAllData_concurrent(t *testing.T) {
+	// Arrange
+	storage := newTestStorage(t)
+	batch := agentBatch()
+	batches := [][]entity.Metrics{batch, reversed(batch)}
+	errs := make(chan error, 20*len(batches))
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 20; i++ {
+		for _, metrics := range batches {
+			wg.Add(1)
+			go func(metrics []entity.Metrics) {
+				defer wg.Done()
+				errs <- storage.SaveAllData(context.Background(), metrics)
+			}(metrics)
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+// TestStorage_IncrementCounter_concurrent adds to one counter and one gauge at once, no addition is lost,
+// it needs a migrated database in BENCH_DATABASE_DSN
+func TestStorage_IncrementCounter_concurrent(t *testing.T) {
+	// Arrange
+	storage := newTestStorage(t)
+	ctx := context.Background()
+	name := fmt.Sprintf("test_increment_%d", time.Now().UnixNano())
+	errs := make(chan error, 2*50)
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := storage.IncrementCounter(ctx, name, 1)
+			errs <- err
+			_, err = storage.AddGauge(ctx, name, 0.5)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	counterValue, _ := storage.GetCounter(ctx, name)
+	assert.Equal(t, int64(50), counterValue)
+	gaugeValue, _ := storage.GetGauge(ctx, name)
+	assert.Equal(t, 25.0, gaugeValue)
+}
+
+// BenchmarkStorage_SaveAllData needs a migrated database (run the server with it once) in BENCH_DATABASE_DSN:
+//
+//	BENCH_DATABASE_DSN="host=127.0.0.1 user=go password=go dbname=go sslmode=disable" go test -run ^$ -bench SaveAllData ./internal/adapters/storage/postgresql/
+//
+// "per row" saves every metric in its own transaction like SaveAllData did before the batch
+func BenchmarkStorage_SaveAllData(b *testing.B) {
+	storage := newTestStorage(b)
+	ctx := context.Background()
+	metrics := agentBatch()
+
+	b.Run("per row", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, metric := range metrics {
+				var err error
+				if metric.MType == entity.GaugeMetric {
+					err = storage.SaveGauge(ctx, metric.ID, *metric.Value)
+				} else {
+					err = storage.SaveCounter(ctx, metric.ID, *metric.Delta)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+		b.ReportMetric(float64(b.N*len(metrics))/b.Elapsed().Seconds(), "rows/s")
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := storage.SaveAllData(ctx, metrics); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(b.N*len(metrics))/b.Elapsed().Seconds(), "rows/s")
+	})
+}
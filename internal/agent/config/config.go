@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
@@ -21,10 +23,42 @@ type ConfigAdapter struct {
 	key            string
 	useCryptoKey   bool
 	configFilePath string
+
+	LogTail          []entity.LogTailFile  `json:"log_tail"`
+	LogTailStatePath string                `env:"LOG_TAIL_STATE" json:"log_tail_state"`
+	Probes           []entity.ProbeTarget  `json:"probes"`
+	WatchPaths       []entity.WatchPath    `json:"watch_paths"`
+	Expvar           []entity.ExpvarTarget `json:"expvar"`
+	Collectors       []string              `env:"COLLECTORS" json:"collectors"`
+	ProcfsRoot       string                `env:"PROCFS_ROOT" json:"procfs_root"`
+	SysfsRoot        string                `env:"SYSFS_ROOT" json:"sysfs_root"`
+	AgentIDPath      string                `env:"AGENT_ID_FILE" json:"agent_id_file"`
+	Group            string                `env:"AGENT_GROUP" json:"group"`
+	Mode             string                `env:"AGENT_MODE" json:"mode"`
+	ListenAddress    string                `env:"LISTEN_ADDRESS" json:"listen_address"`
+
+	// settings set by flags, env or the config file win over the server config
+	explicit map[string]bool
+	// settings before the server config is applied
+	local entity.AgentConfig
+	// guards the settings changed by the server config
+	mu *sync.RWMutex
+	// applied server config
+	remote entity.AgentConfig
 }
 
+// names of settings that may be changed by the server
+const (
+	pollSetting       = "poll"
+	reportSetting     = "report"
+	collectorsSetting = "collectors"
+)
+
 func New() (*ConfigAdapter, error) {
-	adapter := ConfigAdapter{}
+	adapter := ConfigAdapter{
+		explicit: make(map[string]bool),
+		mu:       &sync.RWMutex{},
+	}
 	rootCmd := &cobra.Command{
 		Use:   "go-pc-metrics",
 		Short: "metrics",
@@ -39,10 +73,21 @@ func New() (*ConfigAdapter, error) {
 	rootCmd.Flags().IntVarP(&adapter.RateLimit, "limit", "l", 1, "Limit http reg")
 	rootCmd.Flags().StringVarP(&adapter.CryptoKeyPath, "crypto-key", "y", "", "Path to key file")
 	rootCmd.Flags().StringVarP(&adapter.configFilePath, "config", "o", "", "Path to config file")
+	rootCmd.Flags().StringVar(&adapter.LogTailStatePath, "log-tail-state", "./tmp/log-tail-state.json", "Path to file with positions of tailed logs")
+	rootCmd.Flags().StringSliceVar(&adapter.Collectors, "collectors", []string{"psi", "netstat", "memory", "logtail", "probe", "dirwatch", "expvar"}, "Enabled collectors")
+	rootCmd.Flags().StringVar(&adapter.ProcfsRoot, "procfs", "/proc", "Path to procfs")
+	rootCmd.Flags().StringVar(&adapter.SysfsRoot, "sysfs", "/sys", "Path to sysfs")
+	rootCmd.Flags().StringVar(&adapter.AgentIDPath, "agent-id-file", "./tmp/agent-id", "Path to file with the agent ID")
+	rootCmd.Flags().StringVar(&adapter.Group, "group", "", "Group of the agent for the server config")
+	rootCmd.Flags().StringVar(&adapter.Mode, "mode", entity.PushMode, "push metrics to the server or serve them for pull")
+	rootCmd.Flags().StringVar(&adapter.ListenAddress, "listen", ":8081", "HTTP address for the pull mode")
 
 	if err := rootCmd.Execute(); err != nil {
 		return nil, err
 	}
+	for _, setting := range []string{pollSetting, reportSetting, collectorsSetting} {
+		adapter.explicit[setting] = rootCmd.Flags().Changed(setting)
+	}
 
 	// if env var not empty
 	// get data from env
@@ -54,12 +99,14 @@ func New() (*ConfigAdapter, error) {
 		if err != nil {
 			return nil, err
 		}
+		adapter.explicit[reportSetting] = true
 	}
 	if pollInterval, err := getEnvVariable("POLL_INTERVAL"); err == nil {
 		adapter.PollInterval, err = strconv.Atoi(pollInterval)
 		if err != nil {
 			return nil, err
 		}
+		adapter.explicit[pollSetting] = true
 	}
 	if envKey, err := getEnvVariable("KEY"); err == nil {
 		adapter.key = envKey
@@ -73,15 +120,46 @@ func New() (*ConfigAdapter, error) {
 	if pathKey, err := getEnvVariable("CRYPTO_KEY"); err == nil {
 		adapter.CryptoKeyPath = pathKey
 	}
+	if logTailStatePath, err := getEnvVariable("LOG_TAIL_STATE"); err == nil {
+		adapter.LogTailStatePath = logTailStatePath
+	}
+	if collectors, err := getEnvVariable("COLLECTORS"); err == nil {
+		adapter.Collectors = strings.Split(collectors, ",")
+		adapter.explicit[collectorsSetting] = true
+	}
+	if procfsRoot, err := getEnvVariable("PROCFS_ROOT"); err == nil {
+		adapter.ProcfsRoot = procfsRoot
+	}
+	if sysfsRoot, err := getEnvVariable("SYSFS_ROOT"); err == nil {
+		adapter.SysfsRoot = sysfsRoot
+	}
+	if agentIDPath, err := getEnvVariable("AGENT_ID_FILE"); err == nil {
+		adapter.AgentIDPath = agentIDPath
+	}
+	if group, err := getEnvVariable("AGENT_GROUP"); err == nil {
+		adapter.Group = group
+	}
+	if mode, err := getEnvVariable("AGENT_MODE"); err == nil {
+		adapter.Mode = mode
+	}
+	if listenAddress, err := getEnvVariable("LISTEN_ADDRESS"); err == nil {
+		adapter.ListenAddress = listenAddress
+	}
 
 	// get data from config
+	result := &adapter
 	if adapter.configFilePath != "" {
 		cfgFile, err := adapter.readConfig()
 		if err == nil {
-			return &cfgFile, nil
+			result = &cfgFile
 		}
 	}
-	return &adapter, nil
+	result.local = entity.AgentConfig{
+		PollInterval:   result.PollInterval,
+		ReportInterval: result.ReportInterval,
+		Collectors:     result.Collectors,
+	}
+	return result, nil
 }
 
 func (f *ConfigAdapter) GetServerAddress() string {
@@ -93,10 +171,16 @@ func (f *ConfigAdapter) GetServerAddressWithScheme() string {
 }
 
 func (f *ConfigAdapter) GetReportInterval() time.Duration {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
 	return time.Duration(f.ReportInterval) * time.Second
 }
 
 func (f *ConfigAdapter) GetPollInterval() time.Duration {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
 	return time.Duration(f.PollInterval) * time.Second
 }
 
@@ -122,6 +206,103 @@ func (f *ConfigAdapter) GetRateLimit() int {
 	return f.RateLimit
 }
 
+func (f *ConfigAdapter) GetLogTailFiles() []entity.LogTailFile {
+	return f.LogTail
+}
+
+func (f *ConfigAdapter) GetLogTailStatePath() string {
+	return f.LogTailStatePath
+}
+
+func (f *ConfigAdapter) GetProbeTargets() []entity.ProbeTarget {
+	return f.Probes
+}
+
+func (f *ConfigAdapter) GetWatchPaths() []entity.WatchPath {
+	return f.WatchPaths
+}
+
+func (f *ConfigAdapter) GetExpvarTargets() []entity.ExpvarTarget {
+	return f.Expvar
+}
+
+func (f *ConfigAdapter) IsCollectorEnabled(name string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, collector := range f.Collectors {
+		if strings.TrimSpace(collector) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *ConfigAdapter) GetProcfsRoot() string {
+	return f.ProcfsRoot
+}
+
+func (f *ConfigAdapter) GetSysfsRoot() string {
+	return f.SysfsRoot
+}
+
+func (f *ConfigAdapter) GetAgentIDPath() string {
+	return f.AgentIDPath
+}
+
+func (f *ConfigAdapter) GetGroup() string {
+	return f.Group
+}
+
+func (f *ConfigAdapter) GetMode() string {
+	return f.Mode
+}
+
+func (f *ConfigAdapter) GetListenAddress() string {
+	return f.ListenAddress
+}
+
+// GetAgentConfig returns the effective settings with the version of the applied server config
+func (f *ConfigAdapter) GetAgentConfig() entity.AgentConfig {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	collectors := make([]string, 0, len(f.Collectors))
+	for _, collector := range f.Collectors {
+		collectors = append(collectors, strings.TrimSpace(collector))
+	}
+
+	return entity.AgentConfig{
+		Version:        f.remote.Version,
+		PollInterval:   f.PollInterval,
+		ReportInterval: f.ReportInterval,
+		Collectors:     collectors,
+		Relabel:        f.remote.Relabel,
+	}
+}
+
+// ApplyAgentConfig applies the server config over the local settings,
+// the explicitly set settings and the settings missing in the server config stay local
+func (f *ConfigAdapter) ApplyAgentConfig(remote entity.AgentConfig) entity.AgentConfig {
+	f.mu.Lock()
+	f.remote = remote
+	f.PollInterval = f.local.PollInterval
+	if remote.PollInterval > 0 && !f.explicit[pollSetting] {
+		f.PollInterval = remote.PollInterval
+	}
+	f.ReportInterval = f.local.ReportInterval
+	if remote.ReportInterval > 0 && !f.explicit[reportSetting] {
+		f.ReportInterval = remote.ReportInterval
+	}
+	f.Collectors = f.local.Collectors
+	if remote.Collectors != nil && !f.explicit[collectorsSetting] {
+		f.Collectors = remote.Collectors
+	}
+	f.mu.Unlock()
+
+	return f.GetAgentConfig()
+}
+
 func getEnvVariable(varName string) (string, error) {
 	if envVarValue, exists := os.LookupEnv(varName); exists && envVarValue != "" {
 		return envVarValue, nil
@@ -131,6 +312,8 @@ func getEnvVariable(varName string) (string, error) {
 
 func (f *ConfigAdapter) readConfig() (ConfigAdapter, error) {
 	flags := new(ConfigAdapter)
+	flags.explicit = f.explicit
+	flags.mu = f.mu
 
 	data, err := os.ReadFile(f.configFilePath)
 	if err != nil {
@@ -141,5 +324,50 @@ func (f *ConfigAdapter) readConfig() (ConfigAdapter, error) {
 		return *flags, err
 	}
 
+	flags.setCollectorDefaults(f)
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return *flags, err
+	}
+	for key, setting := range map[string]string{
+		"poll_interval":   pollSetting,
+		"report_interval": reportSetting,
+		"collectors":      collectorsSetting,
+	} {
+		if _, ok := keys[key]; ok {
+			flags.explicit[setting] = true
+		}
+	}
+
 	return *flags, nil
 }
+
+// setCollectorDefaults takes the settings of the collectors and the modes missing in the file from flags and env,
+// other settings are replaced by the file
+func (f *ConfigAdapter) setCollectorDefaults(defaults *ConfigAdapter) {
+	if f.LogTailStatePath == "" {
+		f.LogTailStatePath = defaults.LogTailStatePath
+	}
+	if f.Collectors == nil {
+		f.Collectors = defaults.Collectors
+	}
+	if f.ProcfsRoot == "" {
+		f.ProcfsRoot = defaults.ProcfsRoot
+	}
+	if f.SysfsRoot == "" {
+		f.SysfsRoot = defaults.SysfsRoot
+	}
+	if f.AgentIDPath == "" {
+		f.AgentIDPath = defaults.AgentIDPath
+	}
+	if f.Group == "" {
+		f.Group = defaults.Group
+	}
+	if f.Mode == "" {
+		f.Mode = defaults.Mode
+	}
+	if f.ListenAddress == "" {
+		f.ListenAddress = defaults.ListenAddress
+	}
+}
@@ -2,9 +2,12 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -67,3 +70,89 @@ func TestConfigAdapter(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigAdapter_ApplyAgentConfig(t *testing.T) {
+	local := entity.AgentConfig{PollInterval: 2, ReportInterval: 10, Collectors: []string{"psi"}}
+	relabel := []entity.RelabelRule{{Regex: "RandomValue", Action: entity.RelabelDrop}}
+
+	tests := []struct {
+		name     string
+		explicit map[string]bool
+		remote   entity.AgentConfig
+		want     entity.AgentConfig
+	}{
+		{
+			name:   "remote over defaults",
+			remote: entity.AgentConfig{Version: "v2", PollInterval: 5, ReportInterval: 30, Collectors: []string{}, Relabel: relabel},
+			want:   entity.AgentConfig{Version: "v2", PollInterval: 5, ReportInterval: 30, Collectors: []string{}, Relabel: relabel},
+		},
+		{
+			name:     "explicit settings win",
+			explicit: map[string]bool{pollSetting: true, collectorsSetting: true},
+			remote:   entity.AgentConfig{Version: "v2", PollInterval: 5, ReportInterval: 30, Collectors: []string{"memory"}},
+			want:     entity.AgentConfig{Version: "v2", PollInterval: 2, ReportInterval: 30, Collectors: []string{"psi"}},
+		},
+		{
+			name:   "missing settings stay local",
+			remote: entity.AgentConfig{Version: "v3", ReportInterval: 30},
+			want:   entity.AgentConfig{Version: "v3", PollInterval: 2, ReportInterval: 30, Collectors: []string{"psi"}},
+		},
+		{
+			name: "removed remote config",
+			want: entity.AgentConfig{PollInterval: 2, ReportInterval: 10, Collectors: []string{"psi"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			adapter := &ConfigAdapter{
+				PollInterval:   7,
+				ReportInterval: 70,
+				explicit:       tt.explicit,
+				local:          local,
+				mu:             &sync.RWMutex{},
+			}
+
+			// Act
+			effective := adapter.ApplyAgentConfig(tt.remote)
+
+			// Assert
+			assert.Equal(t, tt.want, effective)
+			assert.Equal(t, time.Duration(tt.want.PollInterval)*time.Second, adapter.GetPollInterval())
+			assert.Equal(t, time.Duration(tt.want.ReportInterval)*time.Second, adapter.GetReportInterval())
+		})
+	}
+}
+
+func TestConfigAdapter_readConfig(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "agent.json")
+	if err := os.WriteFile(path, []byte(`{"address": "file:8080", "poll_interval": 4}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	adapter := &ConfigAdapter{
+		HTTPAddress:    "localhost:8080",
+		PollInterval:   2,
+		RateLimit:      1,
+		Collectors:     []string{"psi", "probe"},
+		ProcfsRoot:     "/proc",
+		Mode:           entity.PushMode,
+		configFilePath: path,
+		explicit:       make(map[string]bool),
+		mu:             &sync.RWMutex{},
+	}
+
+	// Act
+	cfgFile, err := adapter.readConfig()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "file:8080", cfgFile.GetServerAddress())
+	assert.Equal(t, 4*time.Second, cfgFile.GetPollInterval())
+	// the file replaces the flags, only the settings of the collectors and the modes keep them
+	assert.Equal(t, 0, cfgFile.GetRateLimit())
+	assert.True(t, cfgFile.IsCollectorEnabled("probe"))
+	assert.Equal(t, "/proc", cfgFile.GetProcfsRoot())
+	assert.Equal(t, entity.PushMode, cfgFile.GetMode())
+	assert.True(t, cfgFile.explicit[pollSetting])
+}
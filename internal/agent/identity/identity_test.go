@@ -0,0 +1,59 @@
+// Stable identifier of the agent
+
+package identity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	saved := filepath.Join(dir, "saved")
+	if err := os.WriteFile(saved, []byte("c0ffee\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		id   string
+	}{
+		{
+			name: "saved",
+			path: saved,
+			id:   "c0ffee",
+		},
+		{
+			name: "first run",
+			path: filepath.Join(dir, "nested", "agent-id"),
+		},
+		{
+			name: "empty file",
+			path: empty,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			id, err := Load(tt.path)
+			again, _ := Load(tt.path)
+
+			// Assert
+			assert.NoError(t, err)
+			if tt.id != "" {
+				assert.Equal(t, tt.id, id)
+			} else {
+				assert.Len(t, id, 2*idLength)
+			}
+			assert.Equal(t, id, again)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package entity
+
+import "time"
+
+// headers sent with every agent request
+const (
+	AgentIDHeader        = "X-Agent-Id"
+	AgentGroupHeader     = "X-Agent-Group"
+	AgentVersionHeader   = "X-Agent-Version"
+	ReportIntervalHeader = "X-Report-Interval"
+)
+
+// agent modes
+const (
+	// the agent sends metrics to the server
+	PushMode = "push"
+	// the server scrapes the agent
+	PullMode = "pull"
+	// path of the agent endpoint in the pull mode
+	ScrapePath = "/scrape/"
+)
+
+// relabel actions
+const (
+	RelabelReplace = "replace"
+	RelabelDrop    = "drop"
+)
+
+// agent status in the registry
+const (
+	AgentOnline  = "online"
+	AgentStale   = "stale"
+	AgentOffline = "offline"
+)
+
+// Agent - registry record of the agent,
+// ReportInterval is in seconds, Metrics is the number of received metric values,
+// Status is computed on read
+type Agent struct {
+	ID             string    `json:"id"`
+	Group          string    `json:"group,omitempty"`
+	Address        string    `json:"address"`
+	Version        string    `json:"version"`
+	ReportInterval int       `json:"report_interval"`
+	Metrics        int64     `json:"metrics"`
+	FirstSeen      time.Time `json:"first_seen"`
+	LastSeen       time.Time `json:"last_seen"`
+	Status         string    `json:"status,omitempty"`
+	// effective config reported by the agent
+	Config *AgentConfig `json:"config,omitempty"`
+}
+
+// AgentConfig - settings pushed from the server to agents,
+// intervals are in seconds, zero values and nil collectors are not set
+type AgentConfig struct {
+	Version        string        `json:"version,omitempty"`
+	PollInterval   int           `json:"poll_interval,omitempty"`
+	ReportInterval int           `json:"report_interval,omitempty"`
+	Collectors     []string      `json:"collectors"`
+	Relabel        []RelabelRule `json:"relabel,omitempty"`
+}
+
+// RelabelRule - rename or drop metrics whose names match Regex,
+// Replacement may use capture groups ($1)
+type RelabelRule struct {
+	Regex       string `json:"regex"`
+	Replacement string `json:"replacement,omitempty"`
+	Action      string `json:"action,omitempty"`
+}
+
+// UpdatesResponse - body of the /updates/ response
+type UpdatesResponse struct {
+	ConfigVersion string `json:"config_version,omitempty"`
+}
@@ -0,0 +1,41 @@
+package entity
+
+// LogTailFile - log file followed by the agent
+type LogTailFile struct {
+	Path  string        `json:"path"`
+	Rules []LogTailRule `json:"rules"`
+}
+
+// LogTailRule - regexp applied to every new line of the file,
+// Name is the counter incremented on match, named groups become gauges if Gauges is set
+type LogTailRule struct {
+	Name   string `json:"name"`
+	Regex  string `json:"regex"`
+	Gauges bool   `json:"gauges"`
+}
+
+// ProbeTarget - endpoint checked by the agent,
+// Address is URL for http probes and host:port for tcp probes
+type ProbeTarget struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Address   string `json:"address"`
+	BodyRegex string `json:"body_regex"`
+	Timeout   int    `json:"timeout"`
+}
+
+// WatchPath - file or directory watched by the agent, Path may be a glob pattern,
+// MaxDepth limits the walk into directories (0 - no limit, 1 - direct children only)
+type WatchPath struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	MaxDepth int    `json:"max_depth"`
+}
+
+// ExpvarTarget - /debug/vars endpoint of a Go service,
+// its numeric fields are reported as <Prefix>.<field>.<subfield>
+type ExpvarTarget struct {
+	URL     string `json:"url"`
+	Prefix  string `json:"prefix"`
+	Timeout int    `json:"timeout"`
+}
@@ -16,4 +16,17 @@ var (
 	ErrNotImplementedServerError = errors.New("not implemented server error")
 	ErrStorageInstance           = errors.New("data is not an instance of storage")
 	ErrConfigFileNotFound        = errors.New("config file not found")
+	ErrInvalidCollectorRule      = errors.New("invalid collector rule")
+	ErrCollectorInstance         = errors.New("data is not an instance of collector")
+	ErrHostnameNotSet            = errors.New("hostname not set")
+	ErrAgentIDNotSet             = errors.New("agent id not set")
+	ErrAgentConfigNotFound       = errors.New("agent config not found")
+	ErrAgentConfigInstance       = errors.New("data is not an instance of agent config source")
+	ErrInvalidRelabelRule        = errors.New("invalid relabel rule")
+	ErrSignMismatch              = errors.New("sign mismatch")
+	ErrScrapeTargetsInstance     = errors.New("data is not an instance of scrape targets")
+	ErrInvalidHistogram          = errors.New("invalid histogram")
+	ErrInvalidSummary            = errors.New("invalid summary")
+	ErrInvalidHistoryRange       = errors.New("invalid history range")
+	ErrMetricNameTooLong         = errors.New("metric name is too long")
 )
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// Sample - stored value of a gauge or a counter at the time it was saved
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// HistoryPoint - samples of a step aggregated, gauges have avg, min, max and last,
+// counters have the increase within the step
+type HistoryPoint struct {
+	Time     time.Time `json:"time"`
+	Avg      *float64  `json:"avg,omitempty"`
+	Min      *float64  `json:"min,omitempty"`
+	Max      *float64  `json:"max,omitempty"`
+	Last     *float64  `json:"last,omitempty"`
+	Increase *int64    `json:"increase,omitempty"`
+}
+
+// History - response of the history API
+type History struct {
+	ID     string         `json:"id"`
+	MType  string         `json:"type"`
+	From   time.Time      `json:"from"`
+	To     time.Time      `json:"to"`
+	Step   float64        `json:"step_seconds"`
+	Points []HistoryPoint `json:"points"`
+}
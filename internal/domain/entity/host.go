@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// BuildInfo - version of the agent binary
+type BuildInfo struct {
+	Version string `json:"version"`
+	Date    string `json:"date"`
+	Commit  string `json:"commit"`
+}
+
+// HostInfo - facts about the agent host,
+// Updated is set by the server on receive
+type HostInfo struct {
+	AgentID     string    `json:"agent_id"`
+	Hostname    string    `json:"hostname"`
+	OS          string    `json:"os"`
+	Platform    string    `json:"platform"`
+	Kernel      string    `json:"kernel"`
+	CPUModel    string    `json:"cpu_model"`
+	CPUCores    int       `json:"cpu_cores"`
+	TotalMemory uint64    `json:"total_memory"`
+	BootTime    uint64    `json:"boot_time"`
+	Agent       BuildInfo `json:"agent"`
+	Updated     time.Time `json:"updated"`
+}
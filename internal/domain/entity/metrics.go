@@ -1,14 +1,131 @@
 package entity
 
+import (
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// types of metrics
+const (
+	GaugeMetric     = "gauge"
+	CounterMetric   = "counter"
+	HistogramMetric = "histogram"
+	SummaryMetric   = "summary"
+)
+
+// MaxMetricNameLength is the length of names in the PostgreSQL storage, series with labels included
+const MaxMetricNameLength = 255
+
 type (
-	GaugeType   map[string]float64
-	CounterType map[string]int64
+	GaugeType     map[string]float64
+	CounterType   map[string]int64
+	HistogramType map[string]HistogramValue
+	SummaryType   map[string]SummaryValue
 )
 
 // MetricsType - types of metrics
 type MetricsType struct {
-	Gauge   GaugeType
-	Counter CounterType
+	Gauge     GaugeType
+	Counter   CounterType
+	Histogram HistogramType `json:",omitempty"`
+	Summary   SummaryType   `json:",omitempty"`
+}
+
+// Copy returns the metrics with copies of the maps, so they may be read after the storage lock is released
+func (m MetricsType) Copy() MetricsType {
+	return MetricsType{
+		Gauge:     maps.Clone(m.Gauge),
+		Counter:   maps.Clone(m.Counter),
+		Histogram: maps.Clone(m.Histogram),
+		Summary:   maps.Clone(m.Summary),
+	}
+}
+
+// HistogramValue - observations counted in buckets,
+// Counts[i] is the number of observations <= Bounds[i] and > Bounds[i-1],
+// the last count is the +Inf bucket
+type HistogramValue struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"`
+	Sum    float64   `json:"sum"`
+	Count  uint64    `json:"count"`
+}
+
+// Validate checks that bounds are increasing and buckets add up to the count
+func (h HistogramValue) Validate() error {
+	if len(h.Counts) != len(h.Bounds)+1 {
+		return ErrInvalidHistogram
+	}
+	for i := 1; i < len(h.Bounds); i++ {
+		if h.Bounds[i] <= h.Bounds[i-1] {
+			return ErrInvalidHistogram
+		}
+	}
+	var count uint64
+	for _, bucketCount := range h.Counts {
+		count += bucketCount
+	}
+	if count != h.Count {
+		return ErrInvalidHistogram
+	}
+	return nil
+}
+
+// Merge adds buckets with equal bounds, otherwise the added histogram is returned
+func (h HistogramValue) Merge(added HistogramValue) HistogramValue {
+	if len(h.Bounds) != len(added.Bounds) || len(h.Counts) != len(added.Counts) {
+		return added
+	}
+	for i := range h.Bounds {
+		if h.Bounds[i] != added.Bounds[i] {
+			return added
+		}
+	}
+
+	merged := HistogramValue{
+		Bounds: append([]float64(nil), added.Bounds...),
+		Counts: make([]uint64, len(added.Counts)),
+		Sum:    h.Sum + added.Sum,
+		Count:  h.Count + added.Count,
+	}
+	for i := range added.Counts {
+		merged.Counts[i] = h.Counts[i] + added.Counts[i]
+	}
+	return merged
+}
+
+// Quantile - value of the quantile (0..1) of observations
+type Quantile struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+// SummaryValue - quantiles calculated by the client with the sum and the count of observations
+type SummaryValue struct {
+	Quantiles []Quantile `json:"quantiles"`
+	Sum       float64    `json:"sum"`
+	Count     uint64     `json:"count"`
+}
+
+// Merge adds the sum and the count, quantiles can't be merged so the added ones are returned
+func (s SummaryValue) Merge(added SummaryValue) SummaryValue {
+	return SummaryValue{
+		Quantiles: added.Quantiles,
+		Sum:       s.Sum + added.Sum,
+		Count:     s.Count + added.Count,
+	}
+}
+
+// Validate checks that quantiles are within 0..1
+func (s SummaryValue) Validate() error {
+	for _, q := range s.Quantiles {
+		if q.Quantile < 0 || q.Quantile > 1 {
+			return ErrInvalidSummary
+		}
+	}
+	return nil
 }
 
 // MetricsURI - for get requests in the url
@@ -20,8 +137,63 @@ type MetricsURI struct {
 
 // Metrics - app metrics
 type Metrics struct {
-	ID    string   `json:"id"`
-	MType string   `json:"type"`
-	Delta *int64   `json:"delta,omitempty"`
-	Value *float64 `json:"value,omitempty"`
+	ID        string          `json:"id"`
+	MType     string          `json:"type"`
+	Delta     *int64          `json:"delta,omitempty"`
+	Value     *float64        `json:"value,omitempty"`
+	Histogram *HistogramValue `json:"histogram,omitempty"`
+	Summary   *SummaryValue   `json:"summary,omitempty"`
+}
+
+// SeriesID - name of a series with labels sorted by name: name{a="1",b="2"}
+func SeriesID(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+strconv.Quote(labels[key]))
+	}
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// ParseSeriesID splits the ID written by SeriesID to the name and the labels,
+// an ID without valid labels is returned as the name
+func ParseSeriesID(id string) (string, map[string]string) {
+	open := strings.IndexByte(id, '{')
+	if open < 0 || !strings.HasSuffix(id, "}") {
+		return id, nil
+	}
+
+	labels := make(map[string]string)
+	rest := id[open+1 : len(id)-1]
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return id, nil
+		}
+		quoted, err := strconv.QuotedPrefix(rest[eq+1:])
+		if err != nil {
+			return id, nil
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return id, nil
+		}
+		labels[rest[:eq]] = value
+
+		rest = rest[eq+1+len(quoted):]
+		if rest != "" && rest[0] != ',' {
+			return id, nil
+		}
+		rest = strings.TrimPrefix(rest, ",")
+	}
+	return id[:open], labels
 }
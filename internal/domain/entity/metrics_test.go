@@ -52,3 +52,121 @@ func TestMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesID(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		labels map[string]string
+		want   string
+	}{
+		{
+			name: "without labels",
+			id:   "node_load1",
+			want: "node_load1",
+		},
+		{
+			name:   "sorted labels",
+			id:     "node_load1",
+			labels: map[string]string{"job": "node", "instance": `db "1"`},
+			want:   `node_load1{instance="db \"1\"",job="node"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := SeriesID(tt.id, tt.labels)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseSeriesID(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantName   string
+		wantLabels map[string]string
+	}{
+		{
+			name:     "without labels",
+			id:       "node_load1",
+			wantName: "node_load1",
+		},
+		{
+			name:       "labels",
+			id:         `node_load1{instance="db \"1\", {x}",job="node"}`,
+			wantName:   "node_load1",
+			wantLabels: map[string]string{"instance": `db "1", {x}`, "job": "node"},
+		},
+		{
+			name:     "invalid labels",
+			id:       "servers.{db-1}",
+			wantName: "servers.{db-1}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			gotName, gotLabels := ParseSeriesID(tt.id)
+
+			// Assert
+			assert.Equal(t, tt.wantName, gotName)
+			assert.Equal(t, tt.wantLabels, gotLabels)
+		})
+	}
+}
+
+func TestHistogramValue_Merge(t *testing.T) {
+	current := HistogramValue{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 1, 0}, Sum: 0.6, Count: 2}
+	added := HistogramValue{Bounds: []float64{0.1, 1}, Counts: []uint64{0, 1, 1}, Sum: 2.5, Count: 2}
+	rebucketed := HistogramValue{Bounds: []float64{0.5}, Counts: []uint64{1, 0}, Sum: 0.2, Count: 1}
+
+	tests := []struct {
+		name    string
+		current HistogramValue
+		added   HistogramValue
+		want    HistogramValue
+	}{
+		{
+			name:    "equal bounds",
+			current: current,
+			added:   added,
+			want:    HistogramValue{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 2, 1}, Sum: 3.1, Count: 4},
+		},
+		{
+			name:    "other bounds",
+			current: current,
+			added:   rebucketed,
+			want:    rebucketed,
+		},
+		{
+			name:  "new histogram",
+			added: added,
+			want:  added,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := tt.current.Merge(tt.added)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSummaryValue_Merge(t *testing.T) {
+	// Arrange
+	quantiles := []Quantile{{Quantile: 0.5, Value: 0.2}, {Quantile: 0.99, Value: 1.4}}
+	current := SummaryValue{Quantiles: []Quantile{{Quantile: 0.5, Value: 0.1}}, Sum: 1, Count: 5}
+
+	// Act
+	got := current.Merge(SummaryValue{Quantiles: quantiles, Sum: 2, Count: 3})
+
+	// Assert
+	assert.Equal(t, SummaryValue{Quantiles: quantiles, Sum: 3, Count: 8}, got)
+}
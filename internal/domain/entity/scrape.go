@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// target health
+const (
+	TargetUnknown = "unknown"
+	TargetUp      = "up"
+	TargetDown    = "down"
+)
+
+// TargetGroup - targets with common labels in a service discovery file
+type TargetGroup struct {
+	Targets []string          `json:"targets" yaml:"targets"`
+	Labels  map[string]string `json:"labels" yaml:"labels"`
+}
+
+// ScrapeTarget - agent scraped by the server in the pull mode,
+// the health fields are set by the scrape manager
+type ScrapeTarget struct {
+	Address      string            `json:"address"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Health       string            `json:"health"`
+	LastScrape   time.Time         `json:"last_scrape"`
+	LastDuration float64           `json:"last_duration_seconds"`
+	LastError    string            `json:"last_error,omitempty"`
+}
@@ -2,36 +2,140 @@
 package agentusecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math/rand"
 	"runtime"
+	"sync"
 
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/mem"
 )
 
+// additional source of metrics, it may be enabled or disabled by the config by name,
+// gauges replace the previous values, counters are increments
+type collector interface {
+	Name() string
+	Collect(ctx context.Context) (entity.MetricsType, error)
+}
+
 type Agent struct {
-	runtime runtime.MemStats
-	metrics entity.MetricsType
+	mu         sync.RWMutex
+	runtime    runtime.MemStats
+	metrics    entity.MetricsType
+	collectors []collector
+	// gauges of the last collectors run
+	collected entity.GaugeType
+	id        string
+	build     entity.BuildInfo
+	// enabled collectors, nil enables all
+	enabled map[string]bool
+	relabel []relabelRule
 }
 
-func New() (*Agent, error) {
+func New(id string, build entity.BuildInfo, c ...any) (*Agent, error) {
+	collectors := make([]collector, 0, len(c))
+	for _, v := range c {
+		collectorInstance, ok := v.(collector)
+		if !ok {
+			return nil, entity.ErrCollectorInstance
+		}
+		collectors = append(collectors, collectorInstance)
+	}
+
 	agentUsecase := &Agent{
 		metrics: entity.MetricsType{
 			Gauge:   make(map[string]float64, 30),
 			Counter: make(map[string]int64, 1),
 		},
+		collectors: collectors,
+		collected:  make(entity.GaugeType),
+		id:         id,
+		build:      build,
 	}
 
 	return agentUsecase, nil
 }
 
 func (a *Agent) UpdateCounter() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	a.metrics.Counter["PollCount"] += 1
 
 	return nil
 }
 
+// ApplyConfig enables collectors by name and sets relabel rules,
+// the previous settings are kept if the rules are invalid
+func (a *Agent) ApplyConfig(collectors []string, rules []entity.RelabelRule) error {
+	compiled, err := compileRelabel(rules)
+	if err != nil {
+		return err
+	}
+
+	var enabled map[string]bool
+	if collectors != nil {
+		enabled = make(map[string]bool, len(collectors))
+		for _, name := range collectors {
+			enabled[name] = true
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.enabled = enabled
+	a.relabel = compiled
+	return nil
+}
+
+// UpdateCollectors merges metrics of enabled collectors,
+// a failed collector does not stop the others
+func (a *Agent) UpdateCollectors(ctx context.Context) error {
+	var errs []error
+	gauge := make(entity.GaugeType)
+
+	for _, c := range a.activeCollectors() {
+		metrics, err := c.Collect(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("collector %s: %w", c.Name(), err))
+		}
+		for name, value := range metrics.Gauge {
+			gauge[name] = value
+		}
+
+		a.mu.Lock()
+		for name, value := range metrics.Counter {
+			a.metrics.Counter[name] += value
+		}
+		a.mu.Unlock()
+	}
+
+	a.mu.Lock()
+	a.collected = gauge
+	a.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+func (a *Agent) activeCollectors() []collector {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	active := make([]collector, 0, len(a.collectors))
+	for _, c := range a.collectors {
+		if a.enabled != nil && !a.enabled[c.Name()] {
+			continue
+		}
+		active = append(active, c)
+	}
+	return active
+}
+
 func (a *Agent) UpdateGauge() error {
 	runtime.ReadMemStats(&a.runtime)
 
@@ -46,7 +150,7 @@ func (a *Agent) UpdateGauge() error {
 
 	}
 
-	a.metrics.Gauge = entity.GaugeType{
+	gauge := entity.GaugeType{
 		"Alloc":            float64(a.runtime.Alloc),
 		"BuckHashSys":      float64(a.runtime.BuckHashSys),
 		"Frees":            float64(a.runtime.Frees),
@@ -80,17 +184,97 @@ func (a *Agent) UpdateGauge() error {
 		"RandomValue":      rand.Float64(),
 	}
 
+	a.mu.Lock()
+	a.metrics.Gauge = gauge
+	a.mu.Unlock()
+
 	return nil
 }
 
+// the maps are copied with relabeled names,
+// the caller may read them while metrics are updated
 func (a *Agent) GetGauge() (entity.GaugeType, error) {
-	return a.metrics.Gauge, nil
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	gauge := make(entity.GaugeType, len(a.metrics.Gauge)+len(a.collected))
+	for _, source := range []entity.GaugeType{a.collected, a.metrics.Gauge} {
+		for name, value := range source {
+			if name, ok := relabel(a.relabel, name); ok {
+				gauge[name] = value
+			}
+		}
+	}
+	return gauge, nil
 }
 
+// counters relabeled to the same name are summed
 func (a *Agent) GetCounter() (entity.CounterType, error) {
-	return a.metrics.Counter, nil
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	counter := make(entity.CounterType, len(a.metrics.Counter))
+	for name, value := range a.metrics.Counter {
+		if name, ok := relabel(a.relabel, name); ok {
+			counter[name] += value
+		}
+	}
+	return counter, nil
+}
+
+func (a *Agent) GetAgentID() string {
+	return a.id
+}
+
+func (a *Agent) GetBuildInfo() entity.BuildInfo {
+	return a.build
+}
+
+// GetHostInfo returns facts about the host and the agent build
+func (a *Agent) GetHostInfo() (entity.HostInfo, error) {
+	hostInfo, err := host.Info()
+	if err != nil {
+		return entity.HostInfo{}, err
+	}
+
+	cpuInfo, err := cpu.Info()
+	if err != nil {
+		return entity.HostInfo{}, err
+	}
+	cpuCores, err := cpu.Counts(true)
+	if err != nil {
+		return entity.HostInfo{}, err
+	}
+
+	memInfo, err := mem.VirtualMemory()
+	if err != nil {
+		return entity.HostInfo{}, err
+	}
+
+	info := entity.HostInfo{
+		Hostname:    hostInfo.Hostname,
+		OS:          hostInfo.OS,
+		Platform:    hostInfo.Platform + " " + hostInfo.PlatformVersion,
+		Kernel:      hostInfo.KernelVersion,
+		CPUCores:    cpuCores,
+		TotalMemory: memInfo.Total,
+		BootTime:    hostInfo.BootTime,
+		AgentID:     a.id,
+		Agent:       a.build,
+	}
+	if len(cpuInfo) > 0 {
+		info.CPUModel = cpuInfo[0].ModelName
+	}
+
+	return info, nil
 }
 
 func (a *Agent) GetAllData() (entity.MetricsType, error) {
-	return a.metrics, nil
+	gauge, _ := a.GetGauge()
+	counter, _ := a.GetCounter()
+
+	return entity.MetricsType{
+		Gauge:   gauge,
+		Counter: counter,
+	}, nil
 }
@@ -0,0 +1,80 @@
+// the business logic of the client side
+
+package agentusecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCollector struct {
+	name    string
+	metrics entity.MetricsType
+}
+
+func (c *fakeCollector) Name() string {
+	return c.name
+}
+
+func (c *fakeCollector) Collect(ctx context.Context) (entity.MetricsType, error) {
+	return c.metrics, nil
+}
+
+func TestAgent_ApplyConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		collectors []string
+		relabel    []entity.RelabelRule
+		gauge      entity.GaugeType
+		counter    entity.CounterType
+	}{
+		{
+			name:    "all collectors",
+			gauge:   entity.GaugeType{"probe_success": 1, "PressureCPUSomeAvg10": 0.5},
+			counter: entity.CounterType{"TcpRetransSegs": 3},
+		},
+		{
+			name:       "only psi",
+			collectors: []string{"psi"},
+			gauge:      entity.GaugeType{"PressureCPUSomeAvg10": 0.5},
+			counter:    entity.CounterType{},
+		},
+		{
+			name:       "only probe",
+			collectors: []string{"probe"},
+			gauge:      entity.GaugeType{"probe_success": 1},
+			counter:    entity.CounterType{},
+		},
+		{
+			name:       "relabel",
+			collectors: []string{"probe"},
+			relabel:    []entity.RelabelRule{{Regex: "probe_(.+)", Replacement: "api_$1"}},
+			gauge:      entity.GaugeType{"api_success": 1},
+			counter:    entity.CounterType{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			agent, _ := New("c0ffee", entity.BuildInfo{},
+				&fakeCollector{name: "probe", metrics: entity.MetricsType{Gauge: entity.GaugeType{"probe_success": 1}}},
+				&fakeCollector{name: "psi", metrics: entity.MetricsType{Gauge: entity.GaugeType{"PressureCPUSomeAvg10": 0.5}}},
+				&fakeCollector{name: "netstat", metrics: entity.MetricsType{Counter: entity.CounterType{"TcpRetransSegs": 3}}},
+			)
+
+			// Act
+			err := agent.ApplyConfig(tt.collectors, tt.relabel)
+			agent.UpdateCollectors(context.Background())
+			gauge, _ := agent.GetGauge()
+			counter, _ := agent.GetCounter()
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.gauge, gauge)
+			assert.Equal(t, tt.counter, counter)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package agentusecase
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+)
+
+type relabelRule struct {
+	regex       *regexp.Regexp
+	replacement string
+	drop        bool
+}
+
+// compileRelabel checks the rules, the regex must match the whole metric name
+func compileRelabel(rules []entity.RelabelRule) ([]relabelRule, error) {
+	compiled := make([]relabelRule, 0, len(rules))
+	for _, rule := range rules {
+		regex, err := regexp.Compile("^(?:" + rule.Regex + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("relabel %s: %w", rule.Regex, err)
+		}
+
+		switch rule.Action {
+		case "", entity.RelabelReplace, entity.RelabelDrop:
+		default:
+			return nil, fmt.Errorf("relabel action %s: %w", rule.Action, entity.ErrInvalidRelabelRule)
+		}
+
+		compiled = append(compiled, relabelRule{
+			regex:       regex,
+			replacement: rule.Replacement,
+			drop:        rule.Action == entity.RelabelDrop,
+		})
+	}
+	return compiled, nil
+}
+
+// relabel applies the rules in order,
+// false means the metric is dropped
+func relabel(rules []relabelRule, name string) (string, bool) {
+	for _, rule := range rules {
+		if !rule.regex.MatchString(name) {
+			continue
+		}
+		if rule.drop {
+			return "", false
+		}
+		name = rule.regex.ReplaceAllString(name, rule.replacement)
+	}
+	return name, name != ""
+}
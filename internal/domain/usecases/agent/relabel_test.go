@@ -0,0 +1,95 @@
+// the business logic of the client side
+
+package agentusecase
+
+import (
+	"testing"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_relabel(t *testing.T) {
+	rules := []entity.RelabelRule{
+		{Regex: "Random.*", Action: entity.RelabelDrop},
+		{Regex: "Heap(.+)", Replacement: "go_heap_$1"},
+		{Regex: "go_heap_Sys", Replacement: "go_heap_system", Action: entity.RelabelReplace},
+	}
+
+	tests := []struct {
+		name   string
+		metric string
+		want   string
+		keep   bool
+	}{
+		{
+			name:   "dropped",
+			metric: "RandomValue",
+			keep:   false,
+		},
+		{
+			name:   "replaced",
+			metric: "HeapAlloc",
+			want:   "go_heap_Alloc",
+			keep:   true,
+		},
+		{
+			name:   "rules in order",
+			metric: "HeapSys",
+			want:   "go_heap_system",
+			keep:   true,
+		},
+		{
+			name:   "whole name must match",
+			metric: "StackHeapInuse",
+			want:   "StackHeapInuse",
+			keep:   true,
+		},
+	}
+	compiled, err := compileRelabel(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			name, keep := relabel(compiled, tt.metric)
+
+			// Assert
+			assert.Equal(t, tt.keep, keep)
+			assert.Equal(t, tt.want, name)
+		})
+	}
+}
+
+func Test_compileRelabel(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []entity.RelabelRule
+		err   bool
+	}{
+		{
+			name:  "positive",
+			rules: []entity.RelabelRule{{Regex: "Alloc", Action: entity.RelabelDrop}},
+		},
+		{
+			name:  "negative regex",
+			rules: []entity.RelabelRule{{Regex: "Alloc("}},
+			err:   true,
+		},
+		{
+			name:  "negative action",
+			rules: []entity.RelabelRule{{Regex: "Alloc", Action: "keep"}},
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := compileRelabel(tt.rules)
+
+			// Assert
+			assert.Equal(t, tt.err, err != nil)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AgentConfigSource is an autogenerated mock type for the agentConfigSource type
+type AgentConfigSource struct {
+	mock.Mock
+}
+
+// GetAgentConfig provides a mock function with given fields: agentID, group
+func (_m *AgentConfigSource) GetAgentConfig(agentID string, group string) (entity.AgentConfig, error) {
+	ret := _m.Called(agentID, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAgentConfig")
+	}
+
+	var r0 entity.AgentConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (entity.AgentConfig, error)); ok {
+		return rf(agentID, group)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) entity.AgentConfig); ok {
+		r0 = rf(agentID, group)
+	} else {
+		r0 = ret.Get(0).(entity.AgentConfig)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(agentID, group)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAgentConfigSource creates a new instance of AgentConfigSource. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAgentConfigSource(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AgentConfigSource {
+	mock := &AgentConfigSource{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -7,6 +7,8 @@ import (
 
 	entity "github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Storage is an autogenerated mock type for the storage type
@@ -14,6 +16,120 @@ type Storage struct {
 	mock.Mock
 }
 
+// AddGauge provides a mock function with given fields: ctx, gaugeName, value
+func (_m *Storage) AddGauge(ctx context.Context, gaugeName string, value float64) (float64, error) {
+	ret := _m.Called(ctx, gaugeName, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddGauge")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) (float64, error)); ok {
+		return rf(ctx, gaugeName, value)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) float64); ok {
+		r0 = rf(ctx, gaugeName, value)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, float64) error); ok {
+		r1 = rf(ctx, gaugeName, value)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddHistogram provides a mock function with given fields: ctx, histogramName, histogramValue
+func (_m *Storage) AddHistogram(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) (entity.HistogramValue, error) {
+	ret := _m.Called(ctx, histogramName, histogramValue)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddHistogram")
+	}
+
+	var r0 entity.HistogramValue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.HistogramValue) (entity.HistogramValue, error)); ok {
+		return rf(ctx, histogramName, histogramValue)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.HistogramValue) entity.HistogramValue); ok {
+		r0 = rf(ctx, histogramName, histogramValue)
+	} else {
+		r0 = ret.Get(0).(entity.HistogramValue)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, entity.HistogramValue) error); ok {
+		r1 = rf(ctx, histogramName, histogramValue)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddSummary provides a mock function with given fields: ctx, summaryName, summaryValue
+func (_m *Storage) AddSummary(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) (entity.SummaryValue, error) {
+	ret := _m.Called(ctx, summaryName, summaryValue)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSummary")
+	}
+
+	var r0 entity.SummaryValue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.SummaryValue) (entity.SummaryValue, error)); ok {
+		return rf(ctx, summaryName, summaryValue)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.SummaryValue) entity.SummaryValue); ok {
+		r0 = rf(ctx, summaryName, summaryValue)
+	} else {
+		r0 = ret.Get(0).(entity.SummaryValue)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, entity.SummaryValue) error); ok {
+		r1 = rf(ctx, summaryName, summaryValue)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllAgents provides a mock function with given fields: ctx
+func (_m *Storage) GetAllAgents(ctx context.Context) ([]entity.Agent, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllAgents")
+	}
+
+	var r0 []entity.Agent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]entity.Agent, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.Agent); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Agent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllData provides a mock function with given fields: ctx
 func (_m *Storage) GetAllData(ctx context.Context) (entity.MetricsType, error) {
 	ret := _m.Called(ctx)
@@ -42,6 +158,36 @@ func (_m *Storage) GetAllData(ctx context.Context) (entity.MetricsType, error) {
 	return r0, r1
 }
 
+// GetAllHostInfo provides a mock function with given fields: ctx
+func (_m *Storage) GetAllHostInfo(ctx context.Context) ([]entity.HostInfo, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllHostInfo")
+	}
+
+	var r0 []entity.HostInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]entity.HostInfo, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.HostInfo); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.HostInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCounter provides a mock function with given fields: ctx, counterName
 func (_m *Storage) GetCounter(ctx context.Context, counterName string) (int64, error) {
 	ret := _m.Called(ctx, counterName)
@@ -98,6 +244,120 @@ func (_m *Storage) GetGauge(ctx context.Context, gaugeName string) (float64, err
 	return r0, r1
 }
 
+// GetHistogram provides a mock function with given fields: ctx, histogramName
+func (_m *Storage) GetHistogram(ctx context.Context, histogramName string) (entity.HistogramValue, error) {
+	ret := _m.Called(ctx, histogramName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHistogram")
+	}
+
+	var r0 entity.HistogramValue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (entity.HistogramValue, error)); ok {
+		return rf(ctx, histogramName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) entity.HistogramValue); ok {
+		r0 = rf(ctx, histogramName)
+	} else {
+		r0 = ret.Get(0).(entity.HistogramValue)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, histogramName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHistory provides a mock function with given fields: ctx, metricType, metricName, from, to
+func (_m *Storage) GetHistory(ctx context.Context, metricType string, metricName string, from time.Time, to time.Time) ([]entity.Sample, error) {
+	ret := _m.Called(ctx, metricType, metricName, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHistory")
+	}
+
+	var r0 []entity.Sample
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, time.Time) ([]entity.Sample, error)); ok {
+		return rf(ctx, metricType, metricName, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, time.Time) []entity.Sample); ok {
+		r0 = rf(ctx, metricType, metricName, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Sample)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, metricType, metricName, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSummary provides a mock function with given fields: ctx, summaryName
+func (_m *Storage) GetSummary(ctx context.Context, summaryName string) (entity.SummaryValue, error) {
+	ret := _m.Called(ctx, summaryName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSummary")
+	}
+
+	var r0 entity.SummaryValue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (entity.SummaryValue, error)); ok {
+		return rf(ctx, summaryName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) entity.SummaryValue); ok {
+		r0 = rf(ctx, summaryName)
+	} else {
+		r0 = ret.Get(0).(entity.SummaryValue)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, summaryName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrementCounter provides a mock function with given fields: ctx, counterName, delta
+func (_m *Storage) IncrementCounter(ctx context.Context, counterName string, delta int64) (int64, error) {
+	ret := _m.Called(ctx, counterName, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementCounter")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) (int64, error)); ok {
+		return rf(ctx, counterName, delta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) int64); ok {
+		r0 = rf(ctx, counterName, delta)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, counterName, delta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Ping provides a mock function with given fields: ctx
 func (_m *Storage) Ping(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -116,6 +376,42 @@ func (_m *Storage) Ping(ctx context.Context) error {
 	return r0
 }
 
+// SaveAgent provides a mock function with given fields: ctx, agent
+func (_m *Storage) SaveAgent(ctx context.Context, agent entity.Agent) error {
+	ret := _m.Called(ctx, agent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAgent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Agent) error); ok {
+		r0 = rf(ctx, agent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveAgentConfig provides a mock function with given fields: ctx, agentID, agentConfig
+func (_m *Storage) SaveAgentConfig(ctx context.Context, agentID string, agentConfig entity.AgentConfig) error {
+	ret := _m.Called(ctx, agentID, agentConfig)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAgentConfig")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.AgentConfig) error); ok {
+		r0 = rf(ctx, agentID, agentConfig)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveAllData provides a mock function with given fields: ctx, metrics
 func (_m *Storage) SaveAllData(ctx context.Context, metrics []entity.Metrics) error {
 	ret := _m.Called(ctx, metrics)
@@ -170,6 +466,60 @@ func (_m *Storage) SaveGauge(ctx context.Context, gaugeName string, gaugeValue f
 	return r0
 }
 
+// SaveHistogram provides a mock function with given fields: ctx, histogramName, histogramValue
+func (_m *Storage) SaveHistogram(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) error {
+	ret := _m.Called(ctx, histogramName, histogramValue)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveHistogram")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.HistogramValue) error); ok {
+		r0 = rf(ctx, histogramName, histogramValue)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveHostInfo provides a mock function with given fields: ctx, hostInfo
+func (_m *Storage) SaveHostInfo(ctx context.Context, hostInfo entity.HostInfo) error {
+	ret := _m.Called(ctx, hostInfo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveHostInfo")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.HostInfo) error); ok {
+		r0 = rf(ctx, hostInfo)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveSummary provides a mock function with given fields: ctx, summaryName, summaryValue
+func (_m *Storage) SaveSummary(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) error {
+	ret := _m.Called(ctx, summaryName, summaryValue)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSummary")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.SummaryValue) error); ok {
+		r0 = rf(ctx, summaryName, summaryValue)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewStorage(t interface {
@@ -3,22 +3,57 @@ package serverusecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
 	"time"
+	"unicode/utf8"
 
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 )
 
+const (
+	// used when the agent did not send its report interval
+	defaultReportInterval = 10 * time.Second
+	// missed reports before the agent is stale and offline
+	staleReports   = 2
+	offlineReports = 5
+	// points of a history request, like the limit of Prometheus
+	maxHistoryPoints = 11000
+)
+
 //go:generate mockery --name storage --exported
 type storage interface {
 	SaveGauge(ctx context.Context, gaugeName string, gaugeValue float64) error
 	GetGauge(ctx context.Context, gaugeName string) (float64, error)
+	AddGauge(ctx context.Context, gaugeName string, value float64) (float64, error)
 
 	SaveCounter(ctx context.Context, counterName string, counterValue int64) error
 	GetCounter(ctx context.Context, counterName string) (int64, error)
+	IncrementCounter(ctx context.Context, counterName string, delta int64) (int64, error)
+
+	SaveHistogram(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) error
+	GetHistogram(ctx context.Context, histogramName string) (entity.HistogramValue, error)
+	AddHistogram(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) (entity.HistogramValue, error)
+
+	SaveSummary(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) error
+	GetSummary(ctx context.Context, summaryName string) (entity.SummaryValue, error)
+	AddSummary(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) (entity.SummaryValue, error)
 
 	GetAllData(ctx context.Context) (entity.MetricsType, error)
+	// SaveAllData saves gauges and counters, histograms and summaries are added like AddHistogram and AddSummary
 	SaveAllData(ctx context.Context, metrics []entity.Metrics) error
 
+	GetHistory(ctx context.Context, metricType, metricName string, from, to time.Time) ([]entity.Sample, error)
+
+	SaveHostInfo(ctx context.Context, hostInfo entity.HostInfo) error
+	GetAllHostInfo(ctx context.Context) ([]entity.HostInfo, error)
+
+	SaveAgent(ctx context.Context, agent entity.Agent) error
+	GetAllAgents(ctx context.Context) ([]entity.Agent, error)
+	SaveAgentConfig(ctx context.Context, agentID string, agentConfig entity.AgentConfig) error
+
 	Ping(ctx context.Context) error
 }
 
@@ -28,20 +63,37 @@ type cfg interface {
 	GetStoreInterval() time.Duration
 }
 
+// configs of agents managed on the server
+//
+//go:generate mockery --name agentConfigSource --exported
+type agentConfigSource interface {
+	GetAgentConfig(agentID, group string) (entity.AgentConfig, error)
+}
+
 type Server struct {
 	storage       storage
 	storeInterval time.Duration
+	agentConfigs  []agentConfigSource
 }
 
-func New(s any, config cfg) (*Server, error) {
+func New(s any, config cfg, a ...any) (*Server, error) {
 	storageInstance, ok := s.(storage)
 	if !ok {
 		return nil, entity.ErrStorageInstance
 	}
+	agentConfigs := make([]agentConfigSource, 0, len(a))
+	for _, v := range a {
+		agentConfigInstance, ok := v.(agentConfigSource)
+		if !ok {
+			return nil, entity.ErrAgentConfigInstance
+		}
+		agentConfigs = append(agentConfigs, agentConfigInstance)
+	}
 
 	return &Server{
 		storage:       storageInstance,
 		storeInterval: time.Duration(1 * time.Second), //config.GetStoreInterval(),
+		agentConfigs:  agentConfigs,
 	}, nil
 }
 
@@ -53,18 +105,44 @@ func (s *Server) GetGaugeUsecase(ctx context.Context, gaugeName string) (float64
 	return s.storage.GetGauge(ctx, gaugeName)
 }
 
+// SaveCounterUsecase adds the value to the counter, the storage increments it atomically
 func (s *Server) SaveCounterUsecase(ctx context.Context, counterName string, counterValue int64) error {
-	// current val + newVal
-	currentCounterValue, err := s.GetCounterUsecase(ctx, counterName)
-	if err != nil && err != entity.ErrMetricNotFound {
+	_, err := s.storage.IncrementCounter(ctx, counterName, counterValue)
+	return err
+}
+
+func (s *Server) GetCounterUsecase(ctx context.Context, counterName string) (int64, error) {
+	return s.storage.GetCounter(ctx, counterName)
+}
+
+// SaveHistogramUsecase adds the buckets, the sum and the count to the stored histogram,
+// a histogram with other bounds replaces the stored one. The storage merges atomically
+func (s *Server) SaveHistogramUsecase(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) error {
+	if err := histogramValue.Validate(); err != nil {
 		return err
 	}
 
-	return s.storage.SaveCounter(ctx, counterName, counterValue+currentCounterValue)
+	_, err := s.storage.AddHistogram(ctx, histogramName, histogramValue)
+	return err
 }
 
-func (s *Server) GetCounterUsecase(ctx context.Context, counterName string) (int64, error) {
-	return s.storage.GetCounter(ctx, counterName)
+func (s *Server) GetHistogramUsecase(ctx context.Context, histogramName string) (entity.HistogramValue, error) {
+	return s.storage.GetHistogram(ctx, histogramName)
+}
+
+// SaveSummaryUsecase adds the sum and the count to the stored summary,
+// quantiles can't be merged so the received ones replace the stored. The storage merges atomically
+func (s *Server) SaveSummaryUsecase(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) error {
+	if err := summaryValue.Validate(); err != nil {
+		return err
+	}
+
+	_, err := s.storage.AddSummary(ctx, summaryName, summaryValue)
+	return err
+}
+
+func (s *Server) GetSummaryUsecase(ctx context.Context, summaryName string) (entity.SummaryValue, error) {
+	return s.storage.GetSummary(ctx, summaryName)
 }
 
 func (s *Server) GetAllDataUsecase(ctx context.Context) (entity.MetricsType, error) {
@@ -87,21 +165,301 @@ func (s *Server) SaveAllDataUsecase(ctx context.Context, metrics []entity.Metric
 }
 
 func (s *Server) SaveAllDataBatchUsecase(ctx context.Context, metrics []entity.Metrics) error {
-	var sumCounter int64
+	if err := checkNames(metrics); err != nil {
+		return err
+	}
+
+	// counters with the same name are summed within the batch
+	sumCounter := make(map[string]int64)
 	for _, val := range metrics {
-		if val.MType == "counter" {
-			sumCounter += *val.Delta
+		if val.MType == entity.CounterMetric {
+			sumCounter[val.ID] += *val.Delta
 		}
 	}
 	for key, val := range metrics {
-		if val.MType == "counter" {
-			metrics[key].Delta = &sumCounter
+		if val.MType == entity.CounterMetric {
+			sum := sumCounter[val.ID]
+			metrics[key].Delta = &sum
+		}
+	}
+
+	// histograms and summaries are merged with the stored ones and within the batch by the storage
+	for _, val := range metrics {
+		switch val.MType {
+		case entity.HistogramMetric:
+			if val.Histogram == nil {
+				return entity.ErrInvalidHistogram
+			}
+			if err := val.Histogram.Validate(); err != nil {
+				return err
+			}
+		case entity.SummaryMetric:
+			if val.Summary == nil {
+				return entity.ErrInvalidSummary
+			}
+			if err := val.Summary.Validate(); err != nil {
+				return err
+			}
 		}
 	}
 
 	return s.storage.SaveAllData(ctx, metrics)
 }
 
+// ReplaceAllDataUsecase saves the metrics of the sources that send cumulative values:
+// gauges and counters are saved like SaveAllDataBatchUsecase,
+// histograms and summaries replace the stored ones instead of being merged
+func (s *Server) ReplaceAllDataUsecase(ctx context.Context, metrics []entity.Metrics) error {
+	if err := checkNames(metrics); err != nil {
+		return err
+	}
+
+	var batch, cumulative []entity.Metrics
+	for _, val := range metrics {
+		switch val.MType {
+		case entity.HistogramMetric:
+			if val.Histogram == nil {
+				return entity.ErrInvalidHistogram
+			}
+			if err := val.Histogram.Validate(); err != nil {
+				return err
+			}
+			cumulative = append(cumulative, val)
+		case entity.SummaryMetric:
+			if val.Summary == nil {
+				return entity.ErrInvalidSummary
+			}
+			if err := val.Summary.Validate(); err != nil {
+				return err
+			}
+			cumulative = append(cumulative, val)
+		default:
+			batch = append(batch, val)
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := s.SaveAllDataBatchUsecase(ctx, batch); err != nil {
+			return err
+		}
+	}
+	for _, val := range cumulative {
+		var err error
+		if val.MType == entity.HistogramMetric {
+			err = s.storage.SaveHistogram(ctx, val.ID, *val.Histogram)
+		} else {
+			err = s.storage.SaveSummary(ctx, val.ID, *val.Summary)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkNames rejects the batch with a name the storage can't keep, so no metric of it is saved
+func checkNames(metrics []entity.Metrics) error {
+	for _, metric := range metrics {
+		if utf8.RuneCountInString(metric.ID) > entity.MaxMetricNameLength {
+			return fmt.Errorf("%w: %.50s...", entity.ErrMetricNameTooLong, metric.ID)
+		}
+	}
+	return nil
+}
+
+// GetHistoryUsecase aggregates samples of a gauge or a counter by steps counted from from,
+// the time of a point is the start of its step, steps without samples are skipped
+func (s *Server) GetHistoryUsecase(ctx context.Context, metricType, metricName string, from, to time.Time, step time.Duration) ([]entity.HistoryPoint, error) {
+	if metricType != entity.GaugeMetric && metricType != entity.CounterMetric {
+		return nil, entity.ErrInputVarIsWrongType
+	}
+	if step <= 0 || !to.After(from) || to.Sub(from)/step >= maxHistoryPoints {
+		return nil, entity.ErrInvalidHistoryRange
+	}
+
+	samples, err := s.storage.GetHistory(ctx, metricType, metricName, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if metricType == entity.GaugeMetric {
+		return aggregateGauge(samples, from, step), nil
+	}
+	return aggregateCounter(samples, from, step), nil
+}
+
+// aggregateGauge calculates avg, min, max and last of every step
+func aggregateGauge(samples []entity.Sample, from time.Time, step time.Duration) []entity.HistoryPoint {
+	points := make([]entity.HistoryPoint, 0)
+	var sum float64
+	var count int
+	for _, sample := range samples {
+		if sample.Time.Before(from) {
+			continue
+		}
+
+		stepStart := from.Add(sample.Time.Sub(from) / step * step)
+		if len(points) == 0 || !points[len(points)-1].Time.Equal(stepStart) {
+			minValue, maxValue := sample.Value, sample.Value
+			points = append(points, entity.HistoryPoint{Time: stepStart, Min: &minValue, Max: &maxValue})
+			sum, count = 0, 0
+		}
+
+		point := &points[len(points)-1]
+		sum += sample.Value
+		count++
+		avg, last := sum/float64(count), sample.Value
+		point.Avg, point.Last = &avg, &last
+		*point.Min = math.Min(*point.Min, sample.Value)
+		*point.Max = math.Max(*point.Max, sample.Value)
+	}
+	return points
+}
+
+// aggregateCounter calculates the increase of every step from the previous sample,
+// a value less than the previous one is a reset and the whole value is the increase
+func aggregateCounter(samples []entity.Sample, from time.Time, step time.Duration) []entity.HistoryPoint {
+	points := make([]entity.HistoryPoint, 0)
+	for i, sample := range samples {
+		var increase float64
+		if i > 0 {
+			increase = sample.Value - samples[i-1].Value
+			if increase < 0 {
+				increase = sample.Value
+			}
+		}
+		if sample.Time.Before(from) {
+			continue
+		}
+
+		stepStart := from.Add(sample.Time.Sub(from) / step * step)
+		if len(points) == 0 || !points[len(points)-1].Time.Equal(stepStart) {
+			var zero int64
+			points = append(points, entity.HistoryPoint{Time: stepStart, Increase: &zero})
+		}
+		*points[len(points)-1].Increase += int64(math.Round(increase))
+	}
+	return points
+}
+
+// AddAllDataUsecase adds the values to the stored metrics,
+// it is used for the sources that send changes instead of values.
+// The storage adds atomically, so concurrent requests don't lose changes
+func (s *Server) AddAllDataUsecase(ctx context.Context, metrics []entity.Metrics) error {
+	if err := checkNames(metrics); err != nil {
+		return err
+	}
+
+	for _, metric := range metrics {
+		switch {
+		case metric.MType == entity.CounterMetric && metric.Delta != nil:
+			if err := s.SaveCounterUsecase(ctx, metric.ID, *metric.Delta); err != nil {
+				return err
+			}
+		case metric.MType == entity.GaugeMetric && metric.Value != nil:
+			if _, err := s.storage.AddGauge(ctx, metric.ID, *metric.Value); err != nil {
+				return err
+			}
+		case metric.MType == entity.HistogramMetric && metric.Histogram != nil:
+			if err := s.SaveHistogramUsecase(ctx, metric.ID, *metric.Histogram); err != nil {
+				return err
+			}
+		case metric.MType == entity.SummaryMetric && metric.Summary != nil:
+			if err := s.SaveSummaryUsecase(ctx, metric.ID, *metric.Summary); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) SaveHostInfoUsecase(ctx context.Context, hostInfo entity.HostInfo) error {
+	if hostInfo.Hostname == "" {
+		return entity.ErrHostnameNotSet
+	}
+	hostInfo.Updated = time.Now()
+
+	return s.storage.SaveHostInfo(ctx, hostInfo)
+}
+
+// GetAllHostInfoUsecase returns hosts sorted by name
+func (s *Server) GetAllHostInfoUsecase(ctx context.Context) ([]entity.HostInfo, error) {
+	hosts, err := s.storage.GetAllHostInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].Hostname < hosts[j].Hostname
+	})
+
+	return hosts, nil
+}
+
+// SaveAgentUsecase registers a request of the agent
+func (s *Server) SaveAgentUsecase(ctx context.Context, agent entity.Agent) error {
+	if agent.ID == "" {
+		return entity.ErrAgentIDNotSet
+	}
+	agent.FirstSeen = time.Now()
+	agent.LastSeen = agent.FirstSeen
+
+	return s.storage.SaveAgent(ctx, agent)
+}
+
+// GetAllAgentsUsecase returns agents sorted by ID with the current status
+func (s *Server) GetAllAgentsUsecase(ctx context.Context) ([]entity.Agent, error) {
+	agents, err := s.storage.GetAllAgents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range agents {
+		agents[i].Status = agentStatus(agents[i], now)
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].ID < agents[j].ID
+	})
+
+	return agents, nil
+}
+
+// GetAgentConfigUsecase returns the config of the agent from the first source that has it
+func (s *Server) GetAgentConfigUsecase(ctx context.Context, agentID, group string) (entity.AgentConfig, error) {
+	for _, source := range s.agentConfigs {
+		agentConfig, err := source.GetAgentConfig(agentID, group)
+		if errors.Is(err, entity.ErrAgentConfigNotFound) {
+			continue
+		}
+		return agentConfig, err
+	}
+	return entity.AgentConfig{}, entity.ErrAgentConfigNotFound
+}
+
+// SaveAgentConfigUsecase saves the effective config reported by the agent
+func (s *Server) SaveAgentConfigUsecase(ctx context.Context, agentID string, agentConfig entity.AgentConfig) error {
+	if agentID == "" {
+		return entity.ErrAgentIDNotSet
+	}
+	return s.storage.SaveAgentConfig(ctx, agentID, agentConfig)
+}
+
+// agentStatus compares the time since the last request with the report interval of the agent
+func agentStatus(agent entity.Agent, now time.Time) string {
+	interval := time.Duration(agent.ReportInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultReportInterval
+	}
+
+	silence := now.Sub(agent.LastSeen)
+	switch {
+	case silence <= staleReports*interval:
+		return entity.AgentOnline
+	case silence <= offlineReports*interval:
+		return entity.AgentStale
+	default:
+		return entity.AgentOffline
+	}
+}
+
 func (s *Server) Ping(ctx context.Context) error {
 	return s.storage.Ping(ctx)
 }
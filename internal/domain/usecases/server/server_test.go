@@ -5,6 +5,7 @@ package serverusecase
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -61,6 +62,7 @@ func TestServer_SaveAllDataBatchUsecase(t *testing.T) {
 
 	cfg.On("GetServerAddress").Return("localhost:8080").Maybe()
 	cfg.On("GetStoreInterval").Return(time.Duration(1 * time.Second)).Maybe()
+	value := 1.0
 
 	tests := []struct {
 		name string
@@ -79,17 +81,26 @@ func TestServer_SaveAllDataBatchUsecase(t *testing.T) {
 			arg:  []entity.Metrics{},
 			err:  errors.New("err"),
 		},
+		{
+			name: "negative name too long",
+			ctx:  context.Background(),
+			arg: []entity.Metrics{
+				{ID: "up", MType: entity.GaugeMetric, Value: &value},
+				{ID: `up{instance="` + strings.Repeat("a", 250) + `"}`, MType: entity.GaugeMetric, Value: &value},
+			},
+			err: entity.ErrMetricNameTooLong,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Arrange
-			saveAllData := storage.On("SaveAllData", mock.Anything, mock.Anything).Return(tt.err)
+			saveAllData := storage.On("SaveAllData", mock.Anything, mock.Anything).Return(tt.err).Maybe()
 
 			// Act
 			err := server.SaveAllDataBatchUsecase(tt.ctx, tt.arg)
 
 			// Assert
-			assert.Equal(t, tt.err, err)
+			assert.ErrorIs(t, err, tt.err)
 
 			// Unset
 			saveAllData.Unset()
@@ -97,6 +108,271 @@ func TestServer_SaveAllDataBatchUsecase(t *testing.T) {
 	}
 }
 
+func TestServer_SaveAllDataBatchUsecase_counters(t *testing.T) {
+	// Arrange
+	storage := mocks.NewStorage(t)
+	server, _ := New(storage, mocks.NewCfg(t))
+	first, second, other := int64(2), int64(3), int64(10)
+	firstSum, otherSum := int64(5), int64(10)
+	// counters are summed by name, other counters of the batch keep their deltas
+	storage.On("SaveAllData", mock.Anything, []entity.Metrics{
+		{ID: "PollCount", MType: entity.CounterMetric, Delta: &firstSum},
+		{ID: "errors", MType: entity.CounterMetric, Delta: &otherSum},
+		{ID: "PollCount", MType: entity.CounterMetric, Delta: &firstSum},
+	}).Return(nil)
+
+	// Act
+	err := server.SaveAllDataBatchUsecase(context.Background(), []entity.Metrics{
+		{ID: "PollCount", MType: entity.CounterMetric, Delta: &first},
+		{ID: "errors", MType: entity.CounterMetric, Delta: &other},
+		{ID: "PollCount", MType: entity.CounterMetric, Delta: &second},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestServer_AddAllDataUsecase(t *testing.T) {
+	delta := int64(3)
+	value := 0.5
+	histogramValue := entity.HistogramValue{Bounds: []float64{1}, Counts: []uint64{1, 0}, Sum: 0.5, Count: 1}
+
+	tests := []struct {
+		name   string
+		addErr error
+		err    error
+	}{
+		{
+			name: "positive",
+		},
+		{
+			name:   "negative",
+			addErr: errors.New("err"),
+			err:    errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			storage := mocks.NewStorage(t)
+			server, _ := New(storage, mocks.NewCfg(t))
+			storage.On("IncrementCounter", mock.Anything, "requests", int64(3)).Return(int64(5), nil)
+			storage.On("AddGauge", mock.Anything, "duration_sum", value).Return(1.5, tt.addErr)
+			storage.On("AddHistogram", mock.Anything, "duration", histogramValue).Return(histogramValue, nil).Maybe()
+
+			// Act
+			err := server.AddAllDataUsecase(context.Background(), []entity.Metrics{
+				{ID: "requests", MType: "counter", Delta: &delta},
+				{ID: "duration_sum", MType: "gauge", Value: &value},
+				{ID: "duration", MType: "histogram", Histogram: &histogramValue},
+			})
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestServer_SaveHistogramUsecase(t *testing.T) {
+	added := entity.HistogramValue{Bounds: []float64{0.1, 1}, Counts: []uint64{0, 1, 1}, Sum: 2.5, Count: 2}
+
+	tests := []struct {
+		name   string
+		value  entity.HistogramValue
+		addErr error
+		err    error
+	}{
+		{
+			name:  "positive",
+			value: added,
+		},
+		{
+			name:   "negative storage",
+			value:  added,
+			addErr: entity.ErrMetricNotFound,
+			err:    entity.ErrMetricNotFound,
+		},
+		{
+			name:  "negative counts",
+			value: entity.HistogramValue{Bounds: []float64{0.1}, Counts: []uint64{1}, Count: 1},
+			err:   entity.ErrInvalidHistogram,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			storage := mocks.NewStorage(t)
+			server, _ := New(storage, mocks.NewCfg(t))
+			if !errors.Is(tt.err, entity.ErrInvalidHistogram) {
+				storage.On("AddHistogram", mock.Anything, "latency", tt.value).Return(tt.value, tt.addErr)
+			}
+
+			// Act
+			err := server.SaveHistogramUsecase(context.Background(), "latency", tt.value)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestServer_SaveSummaryUsecase(t *testing.T) {
+	// Arrange
+	storage := mocks.NewStorage(t)
+	server, _ := New(storage, mocks.NewCfg(t))
+	value := entity.SummaryValue{Quantiles: []entity.Quantile{{Quantile: 0.5, Value: 0.2}, {Quantile: 0.99, Value: 1.4}}, Sum: 2, Count: 3}
+	storage.On("AddSummary", mock.Anything, "latency", value).Return(value, nil)
+
+	// Act
+	err := server.SaveSummaryUsecase(context.Background(), "latency", value)
+	invalidErr := server.SaveSummaryUsecase(context.Background(), "latency", entity.SummaryValue{Quantiles: []entity.Quantile{{Quantile: 2}}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ErrorIs(t, invalidErr, entity.ErrInvalidSummary)
+}
+
+func TestServer_SaveAllDataBatchUsecase_histogram(t *testing.T) {
+	// Arrange
+	storage := mocks.NewStorage(t)
+	server, _ := New(storage, mocks.NewCfg(t))
+	first := entity.HistogramValue{Bounds: []float64{1}, Counts: []uint64{1, 0}, Sum: 0.5, Count: 1}
+	second := entity.HistogramValue{Bounds: []float64{1}, Counts: []uint64{0, 1}, Sum: 2, Count: 1}
+	metrics := []entity.Metrics{
+		{ID: "latency", MType: entity.HistogramMetric, Histogram: &first},
+		{ID: "latency", MType: entity.HistogramMetric, Histogram: &second},
+	}
+	// the storage merges histograms, so they are passed as received
+	storage.On("SaveAllData", mock.Anything, metrics).Return(nil)
+
+	// Act
+	err := server.SaveAllDataBatchUsecase(context.Background(), metrics)
+	invalidErr := server.SaveAllDataBatchUsecase(context.Background(), []entity.Metrics{
+		{ID: "latency", MType: entity.HistogramMetric},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ErrorIs(t, invalidErr, entity.ErrInvalidHistogram)
+}
+
+func TestServer_ReplaceAllDataUsecase(t *testing.T) {
+	// Arrange
+	storage := mocks.NewStorage(t)
+	server, _ := New(storage, mocks.NewCfg(t))
+	value := 7.0
+	histogramValue := entity.HistogramValue{Bounds: []float64{1}, Counts: []uint64{1, 2}, Sum: 4, Count: 3}
+	storage.On("SaveAllData", mock.Anything, []entity.Metrics{
+		{ID: "queue", MType: entity.GaugeMetric, Value: &value},
+	}).Return(nil).Once()
+	// a cumulative histogram replaces the stored one
+	storage.On("SaveHistogram", mock.Anything, "latency", histogramValue).Return(nil).Once()
+
+	// Act
+	err := server.ReplaceAllDataUsecase(context.Background(), []entity.Metrics{
+		{ID: "queue", MType: entity.GaugeMetric, Value: &value},
+		{ID: "latency", MType: entity.HistogramMetric, Histogram: &histogramValue},
+	})
+	invalidErr := server.ReplaceAllDataUsecase(context.Background(), []entity.Metrics{
+		{ID: "queue", MType: entity.GaugeMetric, Value: &value},
+		{ID: "latency", MType: entity.HistogramMetric, Histogram: &entity.HistogramValue{Count: 1}},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ErrorIs(t, invalidErr, entity.ErrInvalidHistogram)
+}
+
+func TestServer_GetHistoryUsecase(t *testing.T) {
+	from := time.Unix(1700000000, 0)
+	at := func(seconds int) time.Time {
+		return from.Add(time.Duration(seconds) * time.Second)
+	}
+	float := func(value float64) *float64 {
+		return &value
+	}
+	integer := func(value int64) *int64 {
+		return &value
+	}
+
+	tests := []struct {
+		name       string
+		metricType string
+		samples    []entity.Sample
+		to         time.Time
+		want       []entity.HistoryPoint
+		err        error
+	}{
+		{
+			name:       "positive gauge",
+			metricType: entity.GaugeMetric,
+			to:         at(120),
+			samples: []entity.Sample{
+				{Time: at(-5), Value: 100},
+				{Time: at(10), Value: 1},
+				{Time: at(20), Value: 3},
+				{Time: at(70), Value: 5},
+			},
+			want: []entity.HistoryPoint{
+				{Time: at(0), Avg: float(2), Min: float(1), Max: float(3), Last: float(3)},
+				{Time: at(60), Avg: float(5), Min: float(5), Max: float(5), Last: float(5)},
+			},
+		},
+		{
+			name:       "positive counter with reset",
+			metricType: entity.CounterMetric,
+			to:         at(120),
+			samples: []entity.Sample{
+				{Time: at(-5), Value: 10},
+				{Time: at(10), Value: 12},
+				{Time: at(20), Value: 15},
+				{Time: at(70), Value: 2},
+			},
+			want: []entity.HistoryPoint{
+				{Time: at(0), Increase: integer(5)},
+				{Time: at(60), Increase: integer(2)},
+			},
+		},
+		{
+			name:       "negative range",
+			metricType: entity.GaugeMetric,
+			to:         at(-60),
+			err:        entity.ErrInvalidHistoryRange,
+		},
+		{
+			name:       "negative too many points",
+			metricType: entity.GaugeMetric,
+			to:         at(60 * maxHistoryPoints),
+			err:        entity.ErrInvalidHistoryRange,
+		},
+		{
+			name:       "negative type",
+			metricType: entity.HistogramMetric,
+			to:         at(120),
+			err:        entity.ErrInputVarIsWrongType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			storage := mocks.NewStorage(t)
+			server, _ := New(storage, mocks.NewCfg(t))
+			if tt.err == nil {
+				storage.On("GetHistory", mock.Anything, tt.metricType, "load", from, tt.to).Return(tt.samples, nil)
+			}
+
+			// Act
+			points, err := server.GetHistoryUsecase(context.Background(), tt.metricType, "load", from, tt.to, time.Minute)
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+			if tt.err == nil {
+				assert.Equal(t, tt.want, points)
+			}
+		})
+	}
+}
+
 func TestServer_GetAllDataUsecase(t *testing.T) {
 	cfg := mocks.NewCfg(t)
 	storage := mocks.NewStorage(t)
@@ -278,18 +554,16 @@ func TestServer_SaveCounterUsecase(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Arrange
-			getCounterUsecase := storage.On("SaveCounter", mock.Anything, mock.Anything, mock.Anything).Return(tt.err)
-			getCounter := storage.On("GetCounter", mock.Anything, mock.Anything).Return(int64(0), tt.err)
+			incrementCounter := storage.On("IncrementCounter", mock.Anything, "cpu", int64(3)).Return(int64(5), tt.err)
 
 			// Act
-			err := server.SaveCounterUsecase(tt.ctx, "cpu", int64(0))
+			err := server.SaveCounterUsecase(tt.ctx, "cpu", int64(3))
 
 			// Assert
 			assert.Equal(t, tt.err, err)
 
 			// Unset
-			getCounterUsecase.Unset()
-			getCounter.Unset()
+			incrementCounter.Unset()
 		})
 	}
 }
@@ -333,3 +607,281 @@ func TestServer_SaveAllDataUsecase(t *testing.T) {
 	}
 	cancel()
 }
+
+func TestServer_SaveHostInfoUsecase(t *testing.T) {
+	tests := []struct {
+		name       string
+		arg        entity.HostInfo
+		storageErr error
+		err        error
+	}{
+		{
+			name: "positive",
+			arg:  entity.HostInfo{Hostname: "web-1", OS: "linux"},
+		},
+		{
+			name: "negative hostname",
+			arg:  entity.HostInfo{OS: "linux"},
+			err:  entity.ErrHostnameNotSet,
+		},
+		{
+			name:       "negative storage",
+			arg:        entity.HostInfo{Hostname: "web-1"},
+			storageErr: errors.New("err"),
+			err:        errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			storage := mocks.NewStorage(t)
+			server, _ := New(storage, mocks.NewCfg(t))
+			storage.On("SaveHostInfo", mock.Anything, mock.MatchedBy(func(h entity.HostInfo) bool {
+				return h.Hostname == tt.arg.Hostname && !h.Updated.IsZero()
+			})).Return(tt.storageErr).Maybe()
+
+			// Act
+			err := server.SaveHostInfoUsecase(context.Background(), tt.arg)
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestServer_GetAllHostInfoUsecase(t *testing.T) {
+	cfg := mocks.NewCfg(t)
+	storage := mocks.NewStorage(t)
+	server, _ := New(storage, cfg)
+
+	tests := []struct {
+		name   string
+		stored []entity.HostInfo
+		want   []entity.HostInfo
+		err    error
+	}{
+		{
+			name:   "positive sorted",
+			stored: []entity.HostInfo{{Hostname: "web-2"}, {Hostname: "db-1"}, {Hostname: "web-1"}},
+			want:   []entity.HostInfo{{Hostname: "db-1"}, {Hostname: "web-1"}, {Hostname: "web-2"}},
+		},
+		{
+			name: "negative",
+			err:  errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			get := storage.On("GetAllHostInfo", mock.Anything).Return(tt.stored, tt.err)
+
+			// Act
+			hosts, err := server.GetAllHostInfoUsecase(context.Background())
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, hosts)
+
+			// Unset
+			get.Unset()
+		})
+	}
+}
+
+func TestServer_SaveAgentUsecase(t *testing.T) {
+	tests := []struct {
+		name       string
+		arg        entity.Agent
+		storageErr error
+		err        error
+	}{
+		{
+			name: "positive",
+			arg:  entity.Agent{ID: "c0ffee", Metrics: 30},
+		},
+		{
+			name: "negative id",
+			arg:  entity.Agent{Metrics: 30},
+			err:  entity.ErrAgentIDNotSet,
+		},
+		{
+			name:       "negative storage",
+			arg:        entity.Agent{ID: "c0ffee"},
+			storageErr: errors.New("err"),
+			err:        errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			storage := mocks.NewStorage(t)
+			server, _ := New(storage, mocks.NewCfg(t))
+			storage.On("SaveAgent", mock.Anything, mock.MatchedBy(func(a entity.Agent) bool {
+				return a.ID == tt.arg.ID && a.Metrics == tt.arg.Metrics && !a.LastSeen.IsZero() && a.FirstSeen.Equal(a.LastSeen)
+			})).Return(tt.storageErr).Maybe()
+
+			// Act
+			err := server.SaveAgentUsecase(context.Background(), tt.arg)
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestServer_GetAllAgentsUsecase(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		stored []entity.Agent
+		want   []entity.Agent
+		err    error
+	}{
+		{
+			name: "positive",
+			stored: []entity.Agent{
+				{ID: "b", ReportInterval: 10, LastSeen: now.Add(-30 * time.Second)},
+				{ID: "a", ReportInterval: 10, LastSeen: now},
+				{ID: "c", ReportInterval: 10, LastSeen: now.Add(-time.Hour)},
+			},
+			want: []entity.Agent{
+				{ID: "a", ReportInterval: 10, LastSeen: now, Status: entity.AgentOnline},
+				{ID: "b", ReportInterval: 10, LastSeen: now.Add(-30 * time.Second), Status: entity.AgentStale},
+				{ID: "c", ReportInterval: 10, LastSeen: now.Add(-time.Hour), Status: entity.AgentOffline},
+			},
+		},
+		{
+			name: "negative",
+			err:  errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			storage := mocks.NewStorage(t)
+			server, _ := New(storage, mocks.NewCfg(t))
+			storage.On("GetAllAgents", mock.Anything).Return(tt.stored, tt.err)
+
+			// Act
+			agents, err := server.GetAllAgentsUsecase(context.Background())
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, agents)
+		})
+	}
+}
+
+func Test_agentStatus(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		agent entity.Agent
+		want  string
+	}{
+		{
+			name:  "online",
+			agent: entity.Agent{ReportInterval: 10, LastSeen: now.Add(-15 * time.Second)},
+			want:  entity.AgentOnline,
+		},
+		{
+			name:  "stale",
+			agent: entity.Agent{ReportInterval: 10, LastSeen: now.Add(-30 * time.Second)},
+			want:  entity.AgentStale,
+		},
+		{
+			name:  "offline",
+			agent: entity.Agent{ReportInterval: 10, LastSeen: now.Add(-time.Minute)},
+			want:  entity.AgentOffline,
+		},
+		{
+			name:  "default interval",
+			agent: entity.Agent{LastSeen: now.Add(-15 * time.Second)},
+			want:  entity.AgentOnline,
+		},
+		{
+			name:  "long interval",
+			agent: entity.Agent{ReportInterval: 60, LastSeen: now.Add(-time.Minute)},
+			want:  entity.AgentOnline,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			status := agentStatus(tt.agent, now)
+
+			// Assert
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
+
+func TestServer_GetAgentConfigUsecase(t *testing.T) {
+	agentConfig := entity.AgentConfig{Version: "5d41402abc4b", PollInterval: 5}
+
+	tests := []struct {
+		name      string
+		sourceErr error
+		want      entity.AgentConfig
+		err       error
+	}{
+		{
+			name: "positive",
+			want: agentConfig,
+		},
+		{
+			name:      "not found",
+			sourceErr: entity.ErrAgentConfigNotFound,
+			err:       entity.ErrAgentConfigNotFound,
+		},
+		{
+			name:      "negative",
+			sourceErr: errors.New("err"),
+			err:       errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			source := mocks.NewAgentConfigSource(t)
+			server, _ := New(mocks.NewStorage(t), mocks.NewCfg(t), source)
+			source.On("GetAgentConfig", "c0ffee", "db").Return(tt.want, tt.sourceErr)
+
+			// Act
+			got, err := server.GetAgentConfigUsecase(context.Background(), "c0ffee", "db")
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNew_agentConfigs(t *testing.T) {
+	tests := []struct {
+		name   string
+		source any
+		err    error
+	}{
+		{
+			name:   "positive",
+			source: mocks.NewAgentConfigSource(t),
+		},
+		{
+			name:   "negative",
+			source: struct{}{},
+			err:    entity.ErrAgentConfigInstance,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := New(mocks.NewStorage(t), mocks.NewCfg(t), tt.source)
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
@@ -29,6 +30,27 @@ type ConfigAdapter struct {
 	CryptoKeyPath            string `env:"CRYPTO_KEY" json:"crypto_key"`
 	useCryptoKey             bool
 	configFilePath           string
+	AgentConfigPath          string   `env:"AGENT_CONFIG" json:"agent_config"`
+	ScrapeTargets            []string `env:"SCRAPE_TARGETS" json:"scrape_targets"`
+	ScrapeInterval           int      `env:"SCRAPE_INTERVAL" json:"scrape_interval"`
+	ScrapeTimeout            int      `env:"SCRAPE_TIMEOUT" json:"scrape_timeout"`
+	ScrapeSDDir              string   `env:"SCRAPE_SD_DIR" json:"scrape_sd_dir"`
+	ScrapeSDRefresh          int      `env:"SCRAPE_SD_REFRESH" json:"scrape_sd_refresh"`
+	GraphiteAddress          string   `env:"GRAPHITE_ADDRESS" json:"graphite_address"`
+	GraphiteCounters         []string `env:"GRAPHITE_COUNTERS" json:"graphite_counters"`
+	GraphiteReadTimeout      int      `env:"GRAPHITE_READ_TIMEOUT" json:"graphite_read_timeout"`
+	GraphiteMaxLine          int      `env:"GRAPHITE_MAX_LINE" json:"graphite_max_line"`
+	HistorySize              int      `env:"HISTORY_SIZE" json:"history_size"`
+	MaintenanceInterval      int      `env:"MAINTENANCE_INTERVAL" json:"maintenance_interval"`
+	RetentionRaw             int      `env:"RETENTION_RAW" json:"retention_raw"`
+	Retention1m              int      `env:"RETENTION_1M" json:"retention_1m"`
+	Retention1h              int      `env:"RETENTION_1H" json:"retention_1h"`
+	DBMaxConns               int      `env:"DB_MAX_CONNS" json:"db_max_conns"`
+	DBMinConns               int      `env:"DB_MIN_CONNS" json:"db_min_conns"`
+	DBMaxConnLifetime        int      `env:"DB_MAX_CONN_LIFETIME" json:"db_max_conn_lifetime"`
+	DBMaxConnIdleTime        int      `env:"DB_MAX_CONN_IDLE_TIME" json:"db_max_conn_idle_time"`
+	DBHealthCheckPeriod      int      `env:"DB_HEALTH_CHECK_PERIOD" json:"db_health_check_period"`
+	DBStatementCache         int      `env:"DB_STATEMENT_CACHE" json:"db_statement_cache"`
 }
 
 func New() (*ConfigAdapter, error) {
@@ -47,6 +69,27 @@ func New() (*ConfigAdapter, error) {
 	rootCmd.Flags().StringVarP(&adapter.DatabaseConnectionString, "database_dsn", "d", "host=127.0.0.1 user=go password=go dbname=go sslmode=disable", "Database connection string")
 	rootCmd.Flags().StringVarP(&adapter.key, "key", "k", "", "Key string")
 	rootCmd.Flags().StringVarP(&adapter.CryptoKeyPath, "crypto-key", "y", "", "Path to key file")
+	rootCmd.Flags().StringVar(&adapter.AgentConfigPath, "agent-config", "", "Path to file with configs of agents")
+	rootCmd.Flags().StringSliceVar(&adapter.ScrapeTargets, "scrape-targets", nil, "Agents scraped in the pull mode (host:port)")
+	rootCmd.Flags().IntVar(&adapter.ScrapeInterval, "scrape-interval", 10, "Interval for scraping agents")
+	rootCmd.Flags().IntVar(&adapter.ScrapeTimeout, "scrape-timeout", 5, "Timeout of scraping an agent")
+	rootCmd.Flags().StringVar(&adapter.ScrapeSDDir, "scrape-sd-dir", "", "Directory with files of scrape targets (JSON or YAML)")
+	rootCmd.Flags().IntVar(&adapter.ScrapeSDRefresh, "scrape-sd-refresh", 30, "Interval for reading files of scrape targets in addition to watching the directory")
+	rootCmd.Flags().StringVar(&adapter.GraphiteAddress, "graphite-address", "", "TCP and UDP address of the Graphite plaintext listener")
+	rootCmd.Flags().StringSliceVar(&adapter.GraphiteCounters, "graphite-counters", nil, "Regular expressions of Graphite paths saved as counters")
+	rootCmd.Flags().IntVar(&adapter.GraphiteReadTimeout, "graphite-read-timeout", 60, "Timeout of reading a Graphite connection")
+	rootCmd.Flags().IntVar(&adapter.GraphiteMaxLine, "graphite-max-line", 4096, "Max length of a Graphite line")
+	rootCmd.Flags().IntVar(&adapter.HistorySize, "history-size", 1000, "Samples of every series kept in the history of the memory and disk storages")
+	rootCmd.Flags().IntVar(&adapter.MaintenanceInterval, "maintenance-interval", 60, "Interval for rollups and retention of PostgreSQL tables")
+	rootCmd.Flags().IntVar(&adapter.RetentionRaw, "retention-raw", 48, "Hours of raw rows kept in PostgreSQL (0 keeps forever)")
+	rootCmd.Flags().IntVar(&adapter.Retention1m, "retention-1m", 720, "Hours of 1-minute rollups kept in PostgreSQL (0 keeps forever)")
+	rootCmd.Flags().IntVar(&adapter.Retention1h, "retention-1h", 0, "Hours of 1-hour rollups kept in PostgreSQL (0 keeps forever)")
+	rootCmd.Flags().IntVar(&adapter.DBMaxConns, "db-max-conns", 10, "Max connections of the PostgreSQL pool")
+	rootCmd.Flags().IntVar(&adapter.DBMinConns, "db-min-conns", 0, "Connections kept open by the PostgreSQL pool")
+	rootCmd.Flags().IntVar(&adapter.DBMaxConnLifetime, "db-max-conn-lifetime", 3600, "Seconds after which a PostgreSQL connection is closed")
+	rootCmd.Flags().IntVar(&adapter.DBMaxConnIdleTime, "db-max-conn-idle-time", 1800, "Seconds after which an idle PostgreSQL connection is closed")
+	rootCmd.Flags().IntVar(&adapter.DBHealthCheckPeriod, "db-health-check-period", 60, "Interval for checking idle PostgreSQL connections")
+	rootCmd.Flags().IntVar(&adapter.DBStatementCache, "db-statement-cache", 512, "Prepared statements cached by a PostgreSQL connection (0 disables the cache)")
 
 	if err := rootCmd.Execute(); err != nil {
 		return nil, err
@@ -89,6 +132,117 @@ func New() (*ConfigAdapter, error) {
 	if pathKey, err := getEnvVariable("CRYPTO_KEY"); err == nil {
 		adapter.CryptoKeyPath = pathKey
 	}
+	if agentConfigPath, err := getEnvVariable("AGENT_CONFIG"); err == nil {
+		adapter.AgentConfigPath = agentConfigPath
+	}
+	if scrapeTargets, err := getEnvVariable("SCRAPE_TARGETS"); err == nil {
+		adapter.ScrapeTargets = strings.Split(scrapeTargets, ",")
+	}
+	if scrapeInterval, err := getEnvVariable("SCRAPE_INTERVAL"); err == nil {
+		adapter.ScrapeInterval, err = strconv.Atoi(scrapeInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if scrapeTimeout, err := getEnvVariable("SCRAPE_TIMEOUT"); err == nil {
+		adapter.ScrapeTimeout, err = strconv.Atoi(scrapeTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if scrapeSDDir, err := getEnvVariable("SCRAPE_SD_DIR"); err == nil {
+		adapter.ScrapeSDDir = scrapeSDDir
+	}
+	if scrapeSDRefresh, err := getEnvVariable("SCRAPE_SD_REFRESH"); err == nil {
+		adapter.ScrapeSDRefresh, err = strconv.Atoi(scrapeSDRefresh)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if graphiteAddress, err := getEnvVariable("GRAPHITE_ADDRESS"); err == nil {
+		adapter.GraphiteAddress = graphiteAddress
+	}
+	if graphiteCounters, err := getEnvVariable("GRAPHITE_COUNTERS"); err == nil {
+		adapter.GraphiteCounters = strings.Split(graphiteCounters, ",")
+	}
+	if graphiteReadTimeout, err := getEnvVariable("GRAPHITE_READ_TIMEOUT"); err == nil {
+		adapter.GraphiteReadTimeout, err = strconv.Atoi(graphiteReadTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if graphiteMaxLine, err := getEnvVariable("GRAPHITE_MAX_LINE"); err == nil {
+		adapter.GraphiteMaxLine, err = strconv.Atoi(graphiteMaxLine)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if historySize, err := getEnvVariable("HISTORY_SIZE"); err == nil {
+		adapter.HistorySize, err = strconv.Atoi(historySize)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if maintenanceInterval, err := getEnvVariable("MAINTENANCE_INTERVAL"); err == nil {
+		adapter.MaintenanceInterval, err = strconv.Atoi(maintenanceInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if retentionRaw, err := getEnvVariable("RETENTION_RAW"); err == nil {
+		adapter.RetentionRaw, err = strconv.Atoi(retentionRaw)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if retention1m, err := getEnvVariable("RETENTION_1M"); err == nil {
+		adapter.Retention1m, err = strconv.Atoi(retention1m)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if retention1h, err := getEnvVariable("RETENTION_1H"); err == nil {
+		adapter.Retention1h, err = strconv.Atoi(retention1h)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dbMaxConns, err := getEnvVariable("DB_MAX_CONNS"); err == nil {
+		adapter.DBMaxConns, err = strconv.Atoi(dbMaxConns)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dbMinConns, err := getEnvVariable("DB_MIN_CONNS"); err == nil {
+		adapter.DBMinConns, err = strconv.Atoi(dbMinConns)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dbMaxConnLifetime, err := getEnvVariable("DB_MAX_CONN_LIFETIME"); err == nil {
+		adapter.DBMaxConnLifetime, err = strconv.Atoi(dbMaxConnLifetime)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dbMaxConnIdleTime, err := getEnvVariable("DB_MAX_CONN_IDLE_TIME"); err == nil {
+		adapter.DBMaxConnIdleTime, err = strconv.Atoi(dbMaxConnIdleTime)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dbHealthCheckPeriod, err := getEnvVariable("DB_HEALTH_CHECK_PERIOD"); err == nil {
+		adapter.DBHealthCheckPeriod, err = strconv.Atoi(dbHealthCheckPeriod)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dbStatementCache, err := getEnvVariable("DB_STATEMENT_CACHE"); err == nil {
+		adapter.DBStatementCache, err = strconv.Atoi(dbStatementCache)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// get data from config
 	if adapter.configFilePath != "" {
@@ -149,6 +303,114 @@ func (f *ConfigAdapter) UseCryptoKey() bool {
 	return f.useCryptoKey
 }
 
+func (f *ConfigAdapter) GetAgentConfigPath() string {
+	return f.AgentConfigPath
+}
+
+func (f *ConfigAdapter) GetScrapeTargets() []string {
+	return f.ScrapeTargets
+}
+
+func (f *ConfigAdapter) GetScrapeInterval() time.Duration {
+	return time.Duration(f.ScrapeInterval) * time.Second
+}
+
+func (f *ConfigAdapter) GetScrapeTimeout() time.Duration {
+	return time.Duration(f.ScrapeTimeout) * time.Second
+}
+
+func (f *ConfigAdapter) GetScrapeSDDir() string {
+	return f.ScrapeSDDir
+}
+
+func (f *ConfigAdapter) GetScrapeSDRefresh() time.Duration {
+	if f.ScrapeSDRefresh == 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(f.ScrapeSDRefresh) * time.Second
+}
+
+func (f *ConfigAdapter) GetGraphiteAddress() string {
+	return f.GraphiteAddress
+}
+
+func (f *ConfigAdapter) GetGraphiteCounters() []string {
+	return f.GraphiteCounters
+}
+
+func (f *ConfigAdapter) GetGraphiteReadTimeout() time.Duration {
+	if f.GraphiteReadTimeout == 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(f.GraphiteReadTimeout) * time.Second
+}
+
+func (f *ConfigAdapter) GetGraphiteMaxLine() int {
+	if f.GraphiteMaxLine == 0 {
+		return 4096
+	}
+	return f.GraphiteMaxLine
+}
+
+func (f *ConfigAdapter) GetHistorySize() int {
+	return f.HistorySize
+}
+
+func (f *ConfigAdapter) GetMaintenanceInterval() time.Duration {
+	if f.MaintenanceInterval == 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(f.MaintenanceInterval) * time.Second
+}
+
+func (f *ConfigAdapter) GetRetentionRaw() time.Duration {
+	return time.Duration(f.RetentionRaw) * time.Hour
+}
+
+func (f *ConfigAdapter) GetRetention1m() time.Duration {
+	return time.Duration(f.Retention1m) * time.Hour
+}
+
+func (f *ConfigAdapter) GetRetention1h() time.Duration {
+	return time.Duration(f.Retention1h) * time.Hour
+}
+
+func (f *ConfigAdapter) GetDBMaxConns() int32 {
+	if f.DBMaxConns == 0 {
+		return 10
+	}
+	return int32(f.DBMaxConns)
+}
+
+func (f *ConfigAdapter) GetDBMinConns() int32 {
+	return int32(f.DBMinConns)
+}
+
+func (f *ConfigAdapter) GetDBMaxConnLifetime() time.Duration {
+	if f.DBMaxConnLifetime == 0 {
+		return time.Hour
+	}
+	return time.Duration(f.DBMaxConnLifetime) * time.Second
+}
+
+func (f *ConfigAdapter) GetDBMaxConnIdleTime() time.Duration {
+	if f.DBMaxConnIdleTime == 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(f.DBMaxConnIdleTime) * time.Second
+}
+
+func (f *ConfigAdapter) GetDBHealthCheckPeriod() time.Duration {
+	if f.DBHealthCheckPeriod == 0 {
+		return time.Minute
+	}
+	return time.Duration(f.DBHealthCheckPeriod) * time.Second
+}
+
+func (f *ConfigAdapter) GetDBStatementCache() int {
+	return f.DBStatementCache
+}
+
 func getEnvVariable(varName string) (string, error) {
 	if envVarValue, exists := os.LookupEnv(varName); exists && envVarValue != "" {
 		return envVarValue, nil
@@ -0,0 +1,290 @@
+// Graphite plaintext protocol listener
+package graphite
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// lines saved at once by a connection
+	maxBatch = 1000
+	// max size of a UDP datagram
+	maxPacket = 65535
+)
+
+var (
+	errInvalidLine  = errors.New("invalid line")
+	errInvalidValue = errors.New("invalid value")
+	errLineTooLong  = errors.New("line is too long")
+)
+
+//go:generate mockery --name usecase --exported
+type usecase interface {
+	SaveAllDataBatchUsecase(ctx context.Context, metrics []entity.Metrics) error
+	AddAllDataUsecase(ctx context.Context, metrics []entity.Metrics) error
+}
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetGraphiteAddress() string
+	GetGraphiteCounters() []string
+	GetGraphiteReadTimeout() time.Duration
+	GetGraphiteMaxLine() int
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+	Error(msg string, fields ...zapcore.Field)
+}
+
+type Listener struct {
+	usecase     usecase
+	log         log
+	address     string
+	counters    []*regexp.Regexp
+	readTimeout time.Duration
+	maxLine     int
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// New compiles the counter rules, a rule must match the whole path
+func New(u usecase, config cfg, log log) (*Listener, error) {
+	counters := make([]*regexp.Regexp, 0, len(config.GetGraphiteCounters()))
+	for _, rule := range config.GetGraphiteCounters() {
+		regex, err := regexp.Compile("^(?:" + strings.TrimSpace(rule) + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("graphite counter %s: %w", rule, err)
+		}
+		counters = append(counters, regex)
+	}
+
+	return &Listener{
+		usecase:     u,
+		log:         log,
+		address:     config.GetGraphiteAddress(),
+		counters:    counters,
+		readTimeout: config.GetGraphiteReadTimeout(),
+		maxLine:     config.GetGraphiteMaxLine(),
+		conns:       make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Run listens TCP and UDP on the address until ctx is done,
+// then it closes connections and waits for them
+func (l *Listener) Run(ctx context.Context) error {
+	tcpListener, err := net.Listen("tcp", l.address)
+	if err != nil {
+		return err
+	}
+	udpConn, err := net.ListenPacket("udp", l.address)
+	if err != nil {
+		tcpListener.Close()
+		return err
+	}
+	l.log.Info("Graphite listener is running on " + l.address)
+
+	l.wg.Add(2)
+	go l.acceptTCP(ctx, tcpListener)
+	go l.serveUDP(ctx, udpConn)
+
+	<-ctx.Done()
+	tcpListener.Close()
+	udpConn.Close()
+	l.mu.Lock()
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+
+	return nil
+}
+
+func (l *Listener) acceptTCP(ctx context.Context, listener net.Listener) {
+	defer l.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				l.log.Error("graphite accept", zap.Error(err))
+			}
+			return
+		}
+
+		l.mu.Lock()
+		if ctx.Err() != nil {
+			// connections are already closed by Run
+			l.mu.Unlock()
+			conn.Close()
+			return
+		}
+		l.conns[conn] = struct{}{}
+		l.wg.Add(1)
+		l.mu.Unlock()
+		go func() {
+			defer l.wg.Done()
+			l.serveConn(ctx, conn)
+
+			l.mu.Lock()
+			delete(l.conns, conn)
+			l.mu.Unlock()
+		}()
+	}
+}
+
+// serveConn reads lines until the client closes the connection or it is idle for the read timeout,
+// lines are saved when nothing else is buffered or the batch is full
+func (l *Listener) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReaderSize(conn, l.maxLine)
+	batch := make([]entity.Metrics, 0)
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(l.readTimeout)); err != nil {
+			l.log.Error("graphite deadline", zap.Error(err))
+			return
+		}
+
+		line, err := reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			l.log.Error("graphite "+conn.RemoteAddr().String(), zap.Error(errLineTooLong))
+			for errors.Is(err, bufio.ErrBufferFull) {
+				_, err = reader.ReadSlice('\n')
+			}
+			line = nil
+		}
+
+		if metric, parseErr := l.parseLine(string(line)); parseErr != nil {
+			l.log.Error("graphite "+conn.RemoteAddr().String(), zap.Error(parseErr))
+		} else if metric != nil {
+			batch = append(batch, *metric)
+		}
+
+		if err != nil || reader.Buffered() == 0 || len(batch) >= maxBatch {
+			l.save(ctx, batch)
+			batch = batch[:0]
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				l.log.Error("graphite read "+conn.RemoteAddr().String(), zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+// serveUDP saves lines of every datagram
+func (l *Listener) serveUDP(ctx context.Context, conn net.PacketConn) {
+	defer l.wg.Done()
+
+	buf := make([]byte, maxPacket)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				l.log.Error("graphite udp", zap.Error(err))
+			}
+			return
+		}
+
+		batch := make([]entity.Metrics, 0)
+		for _, line := range strings.Split(string(buf[:n]), "\n") {
+			if len(line) > l.maxLine {
+				l.log.Error("graphite "+addr.String(), zap.Error(errLineTooLong))
+				continue
+			}
+			metric, err := l.parseLine(line)
+			if err != nil {
+				l.log.Error("graphite "+addr.String(), zap.Error(err))
+				continue
+			}
+			if metric != nil {
+				batch = append(batch, *metric)
+			}
+		}
+		l.save(ctx, batch)
+	}
+}
+
+// save replaces gauges and adds counters
+func (l *Listener) save(ctx context.Context, batch []entity.Metrics) {
+	// the last value of a gauge wins
+	gauges := make([]entity.Metrics, 0, len(batch))
+	index := make(map[string]int)
+	counters := make([]entity.Metrics, 0)
+	for _, metric := range batch {
+		if metric.MType == entity.CounterMetric {
+			counters = append(counters, metric)
+			continue
+		}
+		if i, ok := index[metric.ID]; ok {
+			gauges[i] = metric
+			continue
+		}
+		index[metric.ID] = len(gauges)
+		gauges = append(gauges, metric)
+	}
+
+	if len(gauges) > 0 {
+		if err := l.usecase.SaveAllDataBatchUsecase(ctx, gauges); err != nil {
+			l.log.Error("graphite save gauges", zap.Error(err))
+		}
+	}
+	if len(counters) > 0 {
+		if err := l.usecase.AddAllDataUsecase(ctx, counters); err != nil {
+			l.log.Error("graphite save counters", zap.Error(err))
+		}
+	}
+}
+
+// parseLine parses "path value [timestamp]", it returns nil for empty lines.
+// Paths matched by the counter rules become counters with the value rounded and added
+func (l *Listener) parseLine(line string) (*entity.Metrics, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, errInvalidLine
+	}
+
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, errInvalidValue
+	}
+	if len(fields) == 3 {
+		// -1 is sent by clients without the time
+		if _, err := strconv.ParseFloat(fields[2], 64); err != nil {
+			return nil, errInvalidLine
+		}
+	}
+
+	path := fields[0]
+	for _, counter := range l.counters {
+		if counter.MatchString(path) {
+			delta := int64(math.Round(value))
+			return &entity.Metrics{ID: path, MType: entity.CounterMetric, Delta: &delta}, nil
+		}
+	}
+	return &entity.Metrics{ID: path, MType: entity.GaugeMetric, Value: &value}, nil
+}
@@ -0,0 +1,171 @@
+// Graphite plaintext protocol listener
+
+package graphite
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/korovindenis/go-pc-metrics/internal/server/graphite/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newListener(t *testing.T, u usecase, counters []string) (*Listener, error) {
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetGraphiteAddress").Return("127.0.0.1:0").Maybe()
+	cfg.On("GetGraphiteCounters").Return(counters)
+	cfg.On("GetGraphiteReadTimeout").Return(time.Second).Maybe()
+	cfg.On("GetGraphiteMaxLine").Return(64).Maybe()
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("").Maybe()
+	log.On("Error", mock.Anything, mock.Anything).Return("").Maybe()
+
+	return New(u, cfg, log)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		counters []string
+		err      bool
+	}{
+		{
+			name:     "positive",
+			counters: []string{`stats_counts\..+`},
+		},
+		{
+			name:     "negative rule",
+			counters: []string{"stats_counts.(+"},
+			err:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			listener, err := newListener(t, mocks.NewUsecase(t), tt.counters)
+
+			// Assert
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, listener)
+		})
+	}
+}
+
+func TestListener_parseLine(t *testing.T) {
+	listener, _ := newListener(t, mocks.NewUsecase(t), []string{`stats_counts\..+`})
+	load := 0.5
+	requests := int64(3)
+
+	tests := []struct {
+		name string
+		line string
+		want *entity.Metrics
+		err  error
+	}{
+		{
+			name: "gauge",
+			line: "servers.db-1.load 0.5 1700000000\n",
+			want: &entity.Metrics{ID: "servers.db-1.load", MType: "gauge", Value: &load},
+		},
+		{
+			name: "counter without time",
+			line: "stats_counts.api.requests 2.6 -1",
+			want: &entity.Metrics{ID: "stats_counts.api.requests", MType: "counter", Delta: &requests},
+		},
+		{
+			name: "empty",
+			line: "\r\n",
+		},
+		{
+			name: "negative value",
+			line: "servers.db-1.load high 1700000000",
+			err:  errInvalidValue,
+		},
+		{
+			name: "negative fields",
+			line: "servers.db-1.load",
+			err:  errInvalidLine,
+		},
+		{
+			name: "negative time",
+			line: "servers.db-1.load 0.5 now",
+			err:  errInvalidLine,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := listener.parseLine(tt.line)
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestListener_serveConn(t *testing.T) {
+	// Arrange
+	var gauges, counters []entity.Metrics
+	u := mocks.NewUsecase(t)
+	u.On("SaveAllDataBatchUsecase", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		gauges = append(gauges, args.Get(1).([]entity.Metrics)...)
+	}).Return(nil)
+	u.On("AddAllDataUsecase", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		counters = append(counters, args.Get(1).([]entity.Metrics)...)
+	}).Return(nil)
+	listener, _ := newListener(t, u, []string{`stats_counts\..+`})
+	server, client := net.Pipe()
+	done := make(chan struct{})
+
+	// Act
+	go func() {
+		listener.serveConn(context.Background(), server)
+		close(done)
+	}()
+	client.Write([]byte("servers.db-1.load 0.5 1700000000\n"))
+	client.Write([]byte("servers.db-1." + strings.Repeat("x", 100) + " 1 1700000000\n"))
+	client.Write([]byte("stats_counts.api.requests 2 1700000000\nservers.db-1.load 0.75 1700000010"))
+	client.Close()
+	<-done
+
+	// Assert
+	got := make(map[string]float64)
+	for _, metric := range gauges {
+		got[metric.ID] = *metric.Value
+	}
+	assert.Equal(t, map[string]float64{"servers.db-1.load": 0.75}, got)
+	assert.Len(t, counters, 1)
+	assert.Equal(t, int64(2), *counters[0].Delta)
+}
+
+func TestListener_Run(t *testing.T) {
+	// Arrange
+	listener, _ := newListener(t, mocks.NewUsecase(t), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	// Act
+	go func() {
+		done <- listener.Run(ctx)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	// Assert
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("listener is not stopped")
+	}
+}
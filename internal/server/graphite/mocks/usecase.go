@@ -0,0 +1,66 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Usecase is an autogenerated mock type for the usecase type
+type Usecase struct {
+	mock.Mock
+}
+
+// AddAllDataUsecase provides a mock function with given fields: ctx, metrics
+func (_m *Usecase) AddAllDataUsecase(ctx context.Context, metrics []entity.Metrics) error {
+	ret := _m.Called(ctx, metrics)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAllDataUsecase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.Metrics) error); ok {
+		r0 = rf(ctx, metrics)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveAllDataBatchUsecase provides a mock function with given fields: ctx, metrics
+func (_m *Usecase) SaveAllDataBatchUsecase(ctx context.Context, metrics []entity.Metrics) error {
+	ret := _m.Called(ctx, metrics)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAllDataBatchUsecase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.Metrics) error); ok {
+		r0 = rf(ctx, metrics)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewUsecase creates a new instance of Usecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Usecase {
+	mock := &Usecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+)
+
+// content type of the Prometheus text format
+const expositionContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// escapes of label values in the Prometheus text format
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// families groups samples by the family name,
+// a series that is equal to an added one after sanitizing is skipped
+type families struct {
+	samples map[string][]string
+	series  map[string]bool
+}
+
+func newFamilies() *families {
+	return &families{
+		samples: make(map[string][]string),
+		series:  make(map[string]bool),
+	}
+}
+
+func (f *families) add(name string, labels []string, samples ...string) {
+	series := name + formatLabels(labels...)
+	if f.series[series] {
+		return
+	}
+	f.series[series] = true
+	f.samples[name] = append(f.samples[name], samples...)
+}
+
+// renderExposition writes metrics in the Prometheus text format: gauges, counters, histograms and summaries,
+// families of every type sorted by name with one # TYPE line. Labels of series IDs become labels of the samples,
+// counters get the _total suffix, a family name that is already written by another type is skipped.
+// Histograms have cumulative _bucket samples with the le label, summaries have quantile samples
+func renderExposition(data entity.MetricsType) string {
+	var b strings.Builder
+	written := make(map[string]bool)
+
+	write := func(metricType string, f *families) {
+		for _, name := range sortedKeys(f.samples) {
+			if written[name] {
+				continue
+			}
+			written[name] = true
+			b.WriteString("# TYPE " + name + " " + metricType + "\n")
+			for _, sample := range f.samples[name] {
+				b.WriteString(sample + "\n")
+			}
+		}
+	}
+
+	gauges := newFamilies()
+	for _, id := range sortedKeys(data.Gauge) {
+		name, labels := splitID(id)
+		gauges.add(name, labels, name+formatLabels(labels...)+" "+formatFloat(data.Gauge[id]))
+	}
+	write(entity.GaugeMetric, gauges)
+
+	counters := newFamilies()
+	for _, id := range sortedKeys(data.Counter) {
+		name, labels := splitID(id)
+		if !strings.HasSuffix(name, "_total") {
+			name += "_total"
+		}
+		counters.add(name, labels, name+formatLabels(labels...)+" "+strconv.FormatInt(data.Counter[id], 10))
+	}
+	write(entity.CounterMetric, counters)
+
+	histograms := newFamilies()
+	for _, id := range sortedKeys(data.Histogram) {
+		name, labels := splitID(id)
+		histogram := data.Histogram[id]
+		samples := make([]string, 0, len(histogram.Counts)+2)
+		var cumulative uint64
+		for i, count := range histogram.Counts {
+			cumulative += count
+			le := "+Inf"
+			if i < len(histogram.Bounds) {
+				le = formatFloat(histogram.Bounds[i])
+			}
+			samples = append(samples, name+"_bucket"+formatLabels(append(labels, `le="`+le+`"`)...)+" "+strconv.FormatUint(cumulative, 10))
+		}
+		samples = append(samples,
+			name+"_sum"+formatLabels(labels...)+" "+formatFloat(histogram.Sum),
+			name+"_count"+formatLabels(labels...)+" "+strconv.FormatUint(histogram.Count, 10),
+		)
+		histograms.add(name, labels, samples...)
+	}
+	write(entity.HistogramMetric, histograms)
+
+	summaries := newFamilies()
+	for _, id := range sortedKeys(data.Summary) {
+		name, labels := splitID(id)
+		summary := data.Summary[id]
+		samples := make([]string, 0, len(summary.Quantiles)+2)
+		for _, q := range summary.Quantiles {
+			samples = append(samples, name+formatLabels(append(labels, `quantile="`+formatFloat(q.Quantile)+`"`)...)+" "+formatFloat(q.Value))
+		}
+		samples = append(samples,
+			name+"_sum"+formatLabels(labels...)+" "+formatFloat(summary.Sum),
+			name+"_count"+formatLabels(labels...)+" "+strconv.FormatUint(summary.Count, 10),
+		)
+		summaries.add(name, labels, samples...)
+	}
+	write(entity.SummaryMetric, summaries)
+
+	return b.String()
+}
+
+// splitID splits the series ID to the sanitized name and the label pairs sorted by name
+func splitID(id string) (string, []string) {
+	name, labels := entity.ParseSeriesID(id)
+	pairs := make([]string, 0, len(labels))
+	for _, key := range sortedKeys(labels) {
+		pairs = append(pairs, sanitizeName(key)+`="`+labelValueReplacer.Replace(labels[key])+`"`)
+	}
+	// pairs has no spare capacity, so appending the le or quantile label copies it
+	return sanitizeName(name), pairs
+}
+
+func formatLabels(pairs ...string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sanitizeName replaces characters that are not allowed in Prometheus names with _,
+// colons are replaced too as they are reserved for recording rules
+func sanitizeName(id string) string {
+	name := []byte(id)
+	for i, ch := range name {
+		isLetter := ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
+		isDigit := ch >= '0' && ch <= '9'
+		if !isLetter && !isDigit {
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 || name[0] >= '0' && name[0] <= '9' {
+		return "_" + string(name)
+	}
+	return string(name)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -9,10 +9,21 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 	"github.com/korovindenis/go-pc-metrics/internal/encrypt"
+	"github.com/korovindenis/go-pc-metrics/internal/server/influx"
+	"github.com/korovindenis/go-pc-metrics/internal/server/otlp"
+	"github.com/korovindenis/go-pc-metrics/internal/server/remotewrite"
+)
+
+const (
+	// defaults of the history query
+	defaultHistoryRange = time.Hour
+	defaultHistoryStep  = time.Minute
 )
 
 //go:generate mockery --name usecase --exported
@@ -23,10 +34,28 @@ type usecase interface {
 	SaveCounterUsecase(ctx context.Context, counterName string, counterValue int64) error
 	GetCounterUsecase(ctx context.Context, counterName string) (int64, error)
 
+	SaveHistogramUsecase(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) error
+	GetHistogramUsecase(ctx context.Context, histogramName string) (entity.HistogramValue, error)
+
+	SaveSummaryUsecase(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) error
+	GetSummaryUsecase(ctx context.Context, summaryName string) (entity.SummaryValue, error)
+
 	SaveAllDataUsecase(ctx context.Context, metrics []entity.Metrics) error
 	GetAllDataUsecase(ctx context.Context) (entity.MetricsType, error)
 
 	SaveAllDataBatchUsecase(ctx context.Context, metrics []entity.Metrics) error
+	AddAllDataUsecase(ctx context.Context, metrics []entity.Metrics) error
+	ReplaceAllDataUsecase(ctx context.Context, metrics []entity.Metrics) error
+
+	GetHistoryUsecase(ctx context.Context, metricType, metricName string, from, to time.Time, step time.Duration) ([]entity.HistoryPoint, error)
+
+	SaveHostInfoUsecase(ctx context.Context, hostInfo entity.HostInfo) error
+	GetAllHostInfoUsecase(ctx context.Context) ([]entity.HostInfo, error)
+
+	SaveAgentUsecase(ctx context.Context, agent entity.Agent) error
+	GetAllAgentsUsecase(ctx context.Context) ([]entity.Agent, error)
+	GetAgentConfigUsecase(ctx context.Context, agentID, group string) (entity.AgentConfig, error)
+	SaveAgentConfigUsecase(ctx context.Context, agentID string, agentConfig entity.AgentConfig) error
 
 	Ping(ctx context.Context) error
 }
@@ -37,18 +66,35 @@ type cfg interface {
 	GetKey() string
 }
 
+//go:generate mockery --name scrapeTargets --exported
+type scrapeTargets interface {
+	GetTargets() []entity.ScrapeTarget
+}
+
 type Handler struct {
 	serverUsecase usecase
 	useCryptoKey  bool
 	cryptoKey     string
+	scrapeTargets scrapeTargets
+	remoteWrite   *remotewrite.Receiver
 }
 
-func New(u usecase, cfg cfg) (*Handler, error) {
-	return &Handler{
+func New(u usecase, cfg cfg, t ...any) (*Handler, error) {
+	handler := &Handler{
 		serverUsecase: u,
 		useCryptoKey:  cfg.UseCryptoKey(),
 		cryptoKey:     cfg.GetKey(),
-	}, nil
+		remoteWrite:   remotewrite.New(),
+	}
+	for _, v := range t {
+		scrapeTargetsInstance, ok := v.(scrapeTargets)
+		if !ok {
+			return nil, entity.ErrScrapeTargetsInstance
+		}
+		handler.scrapeTargets = scrapeTargetsInstance
+	}
+
+	return handler, nil
 }
 
 func (s *Handler) ReceptionMetric(c *gin.Context) {
@@ -68,7 +114,8 @@ func (s *Handler) ReceptionMetric(c *gin.Context) {
 			MType: c.Param("metricType"),
 			ID:    c.Param("metricName"),
 		}
-		if c.Param("metricType") == "counter" {
+		switch c.Param("metricType") {
+		case entity.CounterMetric:
 			metricVal, err := strconv.ParseInt(c.Param("metricVal"), 10, 64)
 			if err != nil {
 				c.Error(fmt.Errorf("%s %w", "ReceptionMetric ParseInt", err))
@@ -77,8 +124,7 @@ func (s *Handler) ReceptionMetric(c *gin.Context) {
 			}
 
 			metrics.Delta = &metricVal
-		}
-		if c.Param("metricType") == "gauge" {
+		case entity.GaugeMetric:
 			metricVal, err := strconv.ParseFloat(c.Param("metricVal"), 64)
 			if err != nil {
 				c.Error(fmt.Errorf("%s %w", "ReceptionMetric ParseFloat", err))
@@ -87,6 +133,10 @@ func (s *Handler) ReceptionMetric(c *gin.Context) {
 			}
 
 			metrics.Value = &metricVal
+		case entity.HistogramMetric, entity.SummaryMetric:
+			// buckets and quantiles can't be passed in the url
+			c.AbortWithError(http.StatusBadRequest, entity.ErrInputVarIsWrongType)
+			return
 		}
 	}
 
@@ -98,7 +148,7 @@ func (s *Handler) ReceptionMetric(c *gin.Context) {
 
 	// run usecases
 	switch metrics.MType {
-	case "gauge":
+	case entity.GaugeMetric:
 		// save metric
 		if err := s.serverUsecase.SaveGaugeUsecase(ctx, metrics.ID, *metrics.Value); err != nil {
 			c.Error(fmt.Errorf("%s %w", "ReceptionMetric SaveGaugeUsecase", err))
@@ -119,7 +169,7 @@ func (s *Handler) ReceptionMetric(c *gin.Context) {
 			return
 		}
 		c.Status(http.StatusOK)
-	case "counter":
+	case entity.CounterMetric:
 		// save metric
 		if err := s.serverUsecase.SaveCounterUsecase(ctx, metrics.ID, *metrics.Delta); err != nil {
 			c.Error(fmt.Errorf("%s %w", "ReceptionMetric SaveCounterUsecase", err))
@@ -140,6 +190,56 @@ func (s *Handler) ReceptionMetric(c *gin.Context) {
 			return
 		}
 		c.Status(http.StatusOK)
+	case entity.HistogramMetric:
+		if metrics.Histogram == nil {
+			c.AbortWithError(http.StatusBadRequest, entity.ErrInvalidHistogram)
+			return
+		}
+		// save metric
+		if err := s.serverUsecase.SaveHistogramUsecase(ctx, metrics.ID, *metrics.Histogram); err != nil {
+			c.Error(fmt.Errorf("%s %w", "ReceptionMetric SaveHistogramUsecase", err))
+			if errors.Is(err, entity.ErrInvalidHistogram) {
+				c.AbortWithError(http.StatusBadRequest, entity.ErrInvalidHistogram)
+				return
+			}
+			c.AbortWithError(http.StatusNotImplemented, entity.ErrNotImplementedServerError)
+			return
+		}
+
+		// show actual metrics
+		histogramVal, err := s.serverUsecase.GetHistogramUsecase(ctx, metrics.ID)
+		if err != nil {
+			c.Error(fmt.Errorf("%s %w", "ReceptionMetric GetHistogramUsecase", err))
+			c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+			return
+		}
+		metrics.Histogram = &histogramVal
+		c.JSON(http.StatusOK, metrics)
+	case entity.SummaryMetric:
+		if metrics.Summary == nil {
+			c.AbortWithError(http.StatusBadRequest, entity.ErrInvalidSummary)
+			return
+		}
+		// save metric
+		if err := s.serverUsecase.SaveSummaryUsecase(ctx, metrics.ID, *metrics.Summary); err != nil {
+			c.Error(fmt.Errorf("%s %w", "ReceptionMetric SaveSummaryUsecase", err))
+			if errors.Is(err, entity.ErrInvalidSummary) {
+				c.AbortWithError(http.StatusBadRequest, entity.ErrInvalidSummary)
+				return
+			}
+			c.AbortWithError(http.StatusNotImplemented, entity.ErrNotImplementedServerError)
+			return
+		}
+
+		// show actual metrics
+		summaryVal, err := s.serverUsecase.GetSummaryUsecase(ctx, metrics.ID)
+		if err != nil {
+			c.Error(fmt.Errorf("%s %w", "ReceptionMetric GetSummaryUsecase", err))
+			c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+			return
+		}
+		metrics.Summary = &summaryVal
+		c.JSON(http.StatusOK, metrics)
 	default:
 		c.AbortWithError(http.StatusNotImplemented, entity.ErrNotImplementedServerError)
 		return
@@ -155,33 +255,195 @@ func (s *Handler) ReceptionMetrics(c *gin.Context) {
 		return
 	}
 
-	var requestBodyBuffer bytes.Buffer
-	teeReader := io.TeeReader(c.Request.Body, &requestBodyBuffer)
+	requestBody, err := s.readBody(c)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionMetrics DecryptData", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
 
-	requestBody, _ := io.ReadAll(teeReader)
-	defer c.Request.Body.Close()
+	if err := json.Unmarshal(requestBody, &metrics); err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionMetrics Unmarshal", err))
+		c.JSON(http.StatusBadRequest, entity.ErrInvalidURLFormat)
+		return
+	}
 
-	if s.useCryptoKey {
-		decryptBody, err := encrypt.Decrypt(s.cryptoKey, string(requestBody))
-		if err != nil {
-			c.Error(fmt.Errorf("%s %w", "ReceptionMetrics DecryptData", err))
+	if err := s.serverUsecase.SaveAllDataBatchUsecase(ctx, metrics); err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionMetrics SaveAllDataBatchUsecase", err))
+		if errors.Is(err, entity.ErrInvalidHistogram) || errors.Is(err, entity.ErrInvalidSummary) || errors.Is(err, entity.ErrMetricNameTooLong) {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+	s.registerAgent(c, len(metrics))
+
+	c.JSON(http.StatusOK, entity.UpdatesResponse{
+		ConfigVersion: s.agentConfigVersion(c),
+	})
+}
+
+// ReceptionRemoteWrite saves samples of the Prometheus remote_write protocol
+func (s *Handler) ReceptionRemoteWrite(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	requestBody, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionRemoteWrite ReadAll", err))
+		c.AbortWithError(http.StatusBadRequest, entity.ErrReadingRequestBody)
+		return
+	}
+
+	metrics, err := s.remoteWrite.Decode(requestBody)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionRemoteWrite Decode", err))
+		c.AbortWithError(http.StatusBadRequest, entity.ErrStatusBadRequest)
+		return
+	}
+
+	if len(metrics) > 0 {
+		if err := s.serverUsecase.SaveAllDataBatchUsecase(ctx, metrics); err != nil {
+			c.Error(fmt.Errorf("%s %w", "ReceptionRemoteWrite SaveAllDataBatchUsecase", err))
+			if errors.Is(err, entity.ErrMetricNameTooLong) {
+				c.AbortWithError(http.StatusBadRequest, entity.ErrMetricNameTooLong)
+				return
+			}
 			c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
 			return
 		}
-		requestBody = []byte(decryptBody)
 	}
 
-	if err := json.Unmarshal(requestBody, &metrics); err != nil {
-		c.Error(fmt.Errorf("%s %w", "ReceptionMetrics Unmarshal", err))
+	c.Status(http.StatusNoContent)
+}
+
+// ReceptionOTLP saves metrics of the OTLP/HTTP protocol in protobuf or JSON
+func (s *Handler) ReceptionOTLP(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	contentType := c.GetHeader("Content-Type")
+	if !strings.HasPrefix(contentType, otlp.ContentTypeProtobuf) && !strings.HasPrefix(contentType, otlp.ContentTypeJSON) {
+		c.AbortWithError(http.StatusUnsupportedMediaType, entity.ErrStatusBadRequest)
+		return
+	}
+
+	requestBody, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionOTLP ReadAll", err))
+		c.AbortWithError(http.StatusBadRequest, entity.ErrReadingRequestBody)
+		return
+	}
+
+	values, increments, err := otlp.Decode(requestBody, contentType)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionOTLP Decode", err))
+		c.AbortWithError(http.StatusBadRequest, entity.ErrStatusBadRequest)
+		return
+	}
+
+	if len(values) > 0 {
+		if err := s.serverUsecase.ReplaceAllDataUsecase(ctx, values); err != nil {
+			c.Error(fmt.Errorf("%s %w", "ReceptionOTLP ReplaceAllDataUsecase", err))
+			if errors.Is(err, entity.ErrMetricNameTooLong) {
+				c.AbortWithError(http.StatusBadRequest, entity.ErrMetricNameTooLong)
+				return
+			}
+			c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+			return
+		}
+	}
+	if len(increments) > 0 {
+		if err := s.serverUsecase.AddAllDataUsecase(ctx, increments); err != nil {
+			c.Error(fmt.Errorf("%s %w", "ReceptionOTLP AddAllDataUsecase", err))
+			if errors.Is(err, entity.ErrMetricNameTooLong) {
+				c.AbortWithError(http.StatusBadRequest, entity.ErrMetricNameTooLong)
+				return
+			}
+			c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+			return
+		}
+	}
+
+	// empty ExportMetricsServiceResponse
+	if strings.HasPrefix(contentType, otlp.ContentTypeJSON) {
+		c.Data(http.StatusOK, otlp.ContentTypeJSON, []byte("{}"))
+		return
+	}
+	c.Data(http.StatusOK, otlp.ContentTypeProtobuf, nil)
+}
+
+// ReceptionInflux saves fields of the InfluxDB v1 line protocol,
+// valid lines are saved when other lines fail like a partial write of InfluxDB
+func (s *Handler) ReceptionInflux(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	requestBody, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionInflux ReadAll", err))
+		c.AbortWithError(http.StatusBadRequest, entity.ErrReadingRequestBody)
+		return
+	}
+
+	metrics, lineErrors, err := influx.Parse(requestBody, c.Query("precision"), time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(metrics) > 0 {
+		if err := s.serverUsecase.SaveAllDataBatchUsecase(ctx, metrics); err != nil {
+			c.Error(fmt.Errorf("%s %w", "ReceptionInflux SaveAllDataBatchUsecase", err))
+			if errors.Is(err, entity.ErrMetricNameTooLong) {
+				c.AbortWithError(http.StatusBadRequest, entity.ErrMetricNameTooLong)
+				return
+			}
+			c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+			return
+		}
+	}
+
+	if len(lineErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("partial write: %d lines failed", len(lineErrors)),
+			"lines": lineErrors,
+		})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Handler) ReceptionHostInfo(c *gin.Context) {
+	var hostInfo entity.HostInfo
+	ctx := c.Request.Context()
+
+	if c.GetHeader("Content-Type") != "application/json" {
 		c.JSON(http.StatusBadRequest, entity.ErrInvalidURLFormat)
 		return
 	}
 
-	if err := s.serverUsecase.SaveAllDataBatchUsecase(ctx, metrics); err != nil {
-		c.Error(fmt.Errorf("%s %w", "ReceptionMetrics SaveAllDataBatchUsecase", err))
+	requestBody, err := s.readBody(c)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionHostInfo DecryptData", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+
+	if err := json.Unmarshal(requestBody, &hostInfo); err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionHostInfo Unmarshal", err))
+		c.JSON(http.StatusBadRequest, entity.ErrInvalidURLFormat)
+		return
+	}
+
+	if err := s.serverUsecase.SaveHostInfoUsecase(ctx, hostInfo); err != nil {
+		if errors.Is(err, entity.ErrHostnameNotSet) {
+			c.AbortWithError(http.StatusBadRequest, entity.ErrStatusBadRequest)
+			return
+		}
+		c.Error(fmt.Errorf("%s %w", "ReceptionHostInfo SaveHostInfoUsecase", err))
 		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
 		return
 	}
+	s.registerAgent(c, 0)
 
 	c.JSON(http.StatusOK, struct{}{})
 }
@@ -212,7 +474,7 @@ func (s *Handler) OutputMetric(c *gin.Context) {
 	}
 
 	switch metrics.MType {
-	case "gauge":
+	case entity.GaugeMetric:
 		// get metric
 		gaugeVal, err := s.serverUsecase.GetGaugeUsecase(ctx, metrics.ID)
 		if err != nil {
@@ -233,7 +495,7 @@ func (s *Handler) OutputMetric(c *gin.Context) {
 			return
 		}
 		c.String(http.StatusOK, strconv.FormatFloat(gaugeVal, 'g', -1, 64))
-	case "counter":
+	case entity.CounterMetric:
 		// get metric
 		counterVal, err := s.serverUsecase.GetCounterUsecase(ctx, metrics.ID)
 		if err != nil {
@@ -254,6 +516,48 @@ func (s *Handler) OutputMetric(c *gin.Context) {
 			return
 		}
 		c.String(http.StatusOK, strconv.FormatInt(counterVal, 10))
+	case entity.HistogramMetric:
+		// get metric
+		histogramVal, err := s.serverUsecase.GetHistogramUsecase(ctx, metrics.ID)
+		if err != nil {
+			if errors.Is(err, entity.ErrMetricNotFound) {
+				c.Error(fmt.Errorf("%s %w", "OutputMetric GetHistogramUsecase ErrMetricNotFound", err))
+				c.AbortWithError(http.StatusNotFound, entity.ErrInputMetricNotFound)
+				return
+			}
+			c.Error(fmt.Errorf("%s %w", "OutputMetric GetHistogramUsecase", err))
+			c.AbortWithError(http.StatusNotImplemented, entity.ErrNotImplementedServerError)
+			return
+		}
+
+		// show metric, the url form has no plain text value
+		metrics.Histogram = &histogramVal
+		if c.Param("metricType") == "" {
+			c.JSON(http.StatusOK, metrics)
+			return
+		}
+		c.JSON(http.StatusOK, histogramVal)
+	case entity.SummaryMetric:
+		// get metric
+		summaryVal, err := s.serverUsecase.GetSummaryUsecase(ctx, metrics.ID)
+		if err != nil {
+			if errors.Is(err, entity.ErrMetricNotFound) {
+				c.Error(fmt.Errorf("%s %w", "OutputMetric GetSummaryUsecase ErrMetricNotFound", err))
+				c.AbortWithError(http.StatusNotFound, entity.ErrInputMetricNotFound)
+				return
+			}
+			c.Error(fmt.Errorf("%s %w", "OutputMetric GetSummaryUsecase", err))
+			c.AbortWithError(http.StatusNotImplemented, entity.ErrNotImplementedServerError)
+			return
+		}
+
+		// show metric, the url form has no plain text value
+		metrics.Summary = &summaryVal
+		if c.Param("metricType") == "" {
+			c.JSON(http.StatusOK, metrics)
+			return
+		}
+		c.JSON(http.StatusOK, summaryVal)
 	default:
 		c.AbortWithError(http.StatusNotImplemented, entity.ErrNotImplementedServerError)
 		return
@@ -273,6 +577,199 @@ func (s *Handler) OutputAllMetrics(c *gin.Context) {
 	})
 }
 
+// OutputMetricsPrometheus returns all metrics in the Prometheus text format
+func (s *Handler) OutputMetricsPrometheus(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, err := s.serverUsecase.GetAllDataUsecase(ctx)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "OutputMetricsPrometheus GetAllDataUsecase", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+	c.Data(http.StatusOK, expositionContentType, []byte(renderExposition(data)))
+}
+
+func (s *Handler) OutputHosts(c *gin.Context) {
+	ctx := c.Request.Context()
+	hosts, err := s.serverUsecase.GetAllHostInfoUsecase(ctx)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "OutputHosts GetAllHostInfoUsecase", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+	c.HTML(http.StatusOK, "hosts.html", gin.H{
+		"Hosts": hosts,
+	})
+}
+
+func (s *Handler) OutputHostsJSON(c *gin.Context) {
+	ctx := c.Request.Context()
+	hosts, err := s.serverUsecase.GetAllHostInfoUsecase(ctx)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "OutputHostsJSON GetAllHostInfoUsecase", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, hosts)
+}
+
+func (s *Handler) OutputAgents(c *gin.Context) {
+	ctx := c.Request.Context()
+	agents, err := s.serverUsecase.GetAllAgentsUsecase(ctx)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "OutputAgents GetAllAgentsUsecase", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+	c.HTML(http.StatusOK, "agents.html", gin.H{
+		"Agents": agents,
+	})
+}
+
+func (s *Handler) OutputAgentsJSON(c *gin.Context) {
+	ctx := c.Request.Context()
+	agents, err := s.serverUsecase.GetAllAgentsUsecase(ctx)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "OutputAgentsJSON GetAllAgentsUsecase", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, agents)
+}
+
+// OutputHistory returns points of a gauge or a counter aggregated by steps.
+// from and to are RFC 3339 times or Unix seconds (the last hour by default),
+// step is a duration like 30s or seconds (a minute by default)
+func (s *Handler) OutputHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	metricType := c.Param("metricType")
+	// the name may have slashes in label values
+	metricName := strings.TrimPrefix(c.Param("metricName"), "/")
+	if metricName == "" {
+		c.AbortWithError(http.StatusNotFound, entity.ErrInvalidURLFormat)
+		return
+	}
+
+	now := time.Now()
+	to, err := parseHistoryTime(c.Query("to"), now)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, entity.ErrInvalidHistoryRange)
+		return
+	}
+	from, err := parseHistoryTime(c.Query("from"), to.Add(-defaultHistoryRange))
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, entity.ErrInvalidHistoryRange)
+		return
+	}
+	step, err := parseHistoryStep(c.Query("step"))
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, entity.ErrInvalidHistoryRange)
+		return
+	}
+
+	points, err := s.serverUsecase.GetHistoryUsecase(ctx, metricType, metricName, from, to, step)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidHistoryRange) || errors.Is(err, entity.ErrInputVarIsWrongType) {
+			c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.Error(fmt.Errorf("%s %w", "OutputHistory GetHistoryUsecase", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, entity.History{
+		ID:     metricName,
+		MType:  metricType,
+		From:   from,
+		To:     to,
+		Step:   step.Seconds(),
+		Points: points,
+	})
+}
+
+func parseHistoryTime(value string, defaultTime time.Time) (time.Time, error) {
+	if value == "" {
+		return defaultTime, nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func parseHistoryStep(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultHistoryStep, nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(value)
+}
+
+// OutputTargetsJSON returns scrape targets of the pull mode with their health
+func (s *Handler) OutputTargetsJSON(c *gin.Context) {
+	targets := []entity.ScrapeTarget{}
+	if s.scrapeTargets != nil {
+		targets = s.scrapeTargets.GetTargets()
+	}
+	c.JSON(http.StatusOK, targets)
+}
+
+// OutputAgentConfig returns the config of the agent that sent the request
+func (s *Handler) OutputAgentConfig(c *gin.Context) {
+	ctx := c.Request.Context()
+	agentConfig, err := s.serverUsecase.GetAgentConfigUsecase(ctx, c.GetHeader(entity.AgentIDHeader), c.GetHeader(entity.AgentGroupHeader))
+	if err != nil {
+		if errors.Is(err, entity.ErrAgentConfigNotFound) {
+			c.AbortWithError(http.StatusNotFound, entity.ErrAgentConfigNotFound)
+			return
+		}
+		c.Error(fmt.Errorf("%s %w", "OutputAgentConfig GetAgentConfigUsecase", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, agentConfig)
+}
+
+// ReceptionAgentConfig saves the effective config reported by the agent
+func (s *Handler) ReceptionAgentConfig(c *gin.Context) {
+	var agentConfig entity.AgentConfig
+	ctx := c.Request.Context()
+
+	if c.GetHeader("Content-Type") != "application/json" {
+		c.JSON(http.StatusBadRequest, entity.ErrInvalidURLFormat)
+		return
+	}
+
+	requestBody, err := s.readBody(c)
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionAgentConfig DecryptData", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+
+	if err := json.Unmarshal(requestBody, &agentConfig); err != nil {
+		c.Error(fmt.Errorf("%s %w", "ReceptionAgentConfig Unmarshal", err))
+		c.JSON(http.StatusBadRequest, entity.ErrInvalidURLFormat)
+		return
+	}
+
+	s.registerAgent(c, 0)
+	if err := s.serverUsecase.SaveAgentConfigUsecase(ctx, c.GetHeader(entity.AgentIDHeader), agentConfig); err != nil {
+		if errors.Is(err, entity.ErrAgentIDNotSet) {
+			c.AbortWithError(http.StatusBadRequest, entity.ErrStatusBadRequest)
+			return
+		}
+		c.Error(fmt.Errorf("%s %w", "ReceptionAgentConfig SaveAgentConfigUsecase", err))
+		c.AbortWithError(http.StatusInternalServerError, entity.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, struct{}{})
+}
+
 func (s *Handler) Ping(c *gin.Context) {
 	if err := s.serverUsecase.Ping(c.Request.Context()); err != nil {
 		c.Error(fmt.Errorf("%s %w", "Ping", err))
@@ -282,3 +779,63 @@ func (s *Handler) Ping(c *gin.Context) {
 
 	c.Status(http.StatusOK)
 }
+
+// readBody returns the request body, decrypted if the crypto key is used
+func (s *Handler) readBody(c *gin.Context) ([]byte, error) {
+	var requestBodyBuffer bytes.Buffer
+	teeReader := io.TeeReader(c.Request.Body, &requestBodyBuffer)
+
+	requestBody, _ := io.ReadAll(teeReader)
+	defer c.Request.Body.Close()
+
+	if s.useCryptoKey {
+		decryptBody, err := encrypt.Decrypt(s.cryptoKey, string(requestBody))
+		if err != nil {
+			return nil, err
+		}
+		requestBody = []byte(decryptBody)
+	}
+
+	return requestBody, nil
+}
+
+// registerAgent updates the registry record of the agent that sent the request,
+// requests without the agent ID are not registered,
+// the registry error does not fail the request
+func (s *Handler) registerAgent(c *gin.Context, metrics int) {
+	agentID := c.GetHeader(entity.AgentIDHeader)
+	if agentID == "" {
+		return
+	}
+	reportInterval, _ := strconv.Atoi(c.GetHeader(entity.ReportIntervalHeader))
+
+	err := s.serverUsecase.SaveAgentUsecase(c.Request.Context(), entity.Agent{
+		ID:             agentID,
+		Group:          c.GetHeader(entity.AgentGroupHeader),
+		Address:        c.ClientIP(),
+		Version:        c.GetHeader(entity.AgentVersionHeader),
+		ReportInterval: reportInterval,
+		Metrics:        int64(metrics),
+	})
+	if err != nil {
+		c.Error(fmt.Errorf("%s %w", "registerAgent SaveAgentUsecase", err))
+	}
+}
+
+// agentConfigVersion returns the version of the agent config,
+// empty if the agent has no config on the server
+func (s *Handler) agentConfigVersion(c *gin.Context) string {
+	agentID := c.GetHeader(entity.AgentIDHeader)
+	if agentID == "" {
+		return ""
+	}
+
+	agentConfig, err := s.serverUsecase.GetAgentConfigUsecase(c.Request.Context(), agentID, c.GetHeader(entity.AgentGroupHeader))
+	if err != nil {
+		if !errors.Is(err, entity.ErrAgentConfigNotFound) {
+			c.Error(fmt.Errorf("%s %w", "agentConfigVersion GetAgentConfigUsecase", err))
+		}
+		return ""
+	}
+	return agentConfig.Version
+}
@@ -4,16 +4,21 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/snappy"
 	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 	"github.com/korovindenis/go-pc-metrics/internal/server/handler/mocks"
+	"github.com/korovindenis/go-pc-metrics/internal/server/remotewrite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/encoding/protowire"
 )
 
 func TestHandler_ReceptionMetric(t *testing.T) {
@@ -117,6 +122,87 @@ func TestHandler_ReceptionMetric(t *testing.T) {
 	}
 }
 
+func TestHandler_ReceptionMetric_histogram(t *testing.T) {
+	histogram := entity.HistogramValue{Bounds: []float64{0.1}, Counts: []uint64{1, 2}, Sum: 0.9, Count: 3}
+	summary := entity.SummaryValue{Quantiles: []entity.Quantile{{Quantile: 0.5, Value: 0.2}}, Sum: 0.9, Count: 3}
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		saveErr    error
+		statusCode int
+	}{
+		{
+			name:       "positive histogram",
+			url:        "/update/",
+			body:       `{"id":"latency","type":"histogram","histogram":{"bounds":[0.1],"counts":[1,2],"sum":0.9,"count":3}}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "positive summary",
+			url:        "/update/",
+			body:       `{"id":"latency","type":"summary","summary":{"quantiles":[{"quantile":0.5,"value":0.2}],"sum":0.9,"count":3}}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "negative invalid histogram",
+			url:        "/update/",
+			body:       `{"id":"latency","type":"histogram","histogram":{"bounds":[0.1],"counts":[1],"sum":0.9,"count":3}}`,
+			saveErr:    entity.ErrInvalidHistogram,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "negative missing value",
+			url:        "/update/",
+			body:       `{"id":"latency","type":"summary"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "negative url",
+			url:        "/update/histogram/latency/3",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			usecase := mocks.NewUsecase(t)
+			cfg := mocks.NewCfg(t)
+			cfg.On("UseCryptoKey").Return(false)
+			cfg.On("GetKey").Return("")
+			handler, _ := New(usecase, cfg)
+			router := gin.Default()
+			router.POST("/update/", handler.ReceptionMetric)
+			router.POST("/update/:metricType/:metricName/:metricVal", handler.ReceptionMetric)
+			usecase.On("SaveHistogramUsecase", mock.Anything, "latency", histogram).Return(nil).Maybe()
+			usecase.On("SaveHistogramUsecase", mock.Anything, "latency", mock.Anything).Return(tt.saveErr).Maybe()
+			usecase.On("GetHistogramUsecase", mock.Anything, "latency").Return(histogram, nil).Maybe()
+			usecase.On("SaveSummaryUsecase", mock.Anything, "latency", summary).Return(nil).Maybe()
+			usecase.On("GetSummaryUsecase", mock.Anything, "latency").Return(summary, nil).Maybe()
+			w := httptest.NewRecorder()
+
+			// Act
+			req, err := http.NewRequest(http.MethodPost, tt.url, bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			if tt.statusCode == http.StatusOK {
+				var got entity.Metrics
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.True(t, got.Histogram != nil || got.Summary != nil)
+			}
+		})
+	}
+}
+
 func TestHandler_Ping(t *testing.T) {
 	usecase := mocks.NewUsecase(t)
 	cfg := mocks.NewCfg(t)
@@ -209,6 +295,176 @@ func TestHandler_OutputAllMetrics(t *testing.T) {
 	}
 }
 
+func TestHandler_OutputMetricsPrometheus(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+	handler, _ := New(usecase, cfg)
+	router := gin.Default()
+	router.GET("/metrics", handler.OutputMetricsPrometheus)
+
+	tests := []struct {
+		name       string
+		data       entity.MetricsType
+		statusCode int
+		body       string
+		err        error
+	}{
+		{
+			name: "positive",
+			data: entity.MetricsType{
+				Gauge:   entity.GaugeType{"scrape.10.0.0.5:8081.up": 1, "Alloc": 1024.5},
+				Counter: entity.CounterType{"PollCount": 5, "requests_total": 7},
+				Histogram: entity.HistogramType{
+					"latency": {Bounds: []float64{0.1, 1}, Counts: []uint64{2, 1, 1}, Sum: 3.5, Count: 4},
+				},
+				Summary: entity.SummaryType{
+					"rpc.duration": {Quantiles: []entity.Quantile{{Quantile: 0.5, Value: 0.2}, {Quantile: 0.99, Value: 1.5}}, Sum: 12, Count: 30},
+				},
+			},
+			statusCode: http.StatusOK,
+			body: "# TYPE Alloc gauge\nAlloc 1024.5\n" +
+				"# TYPE scrape_10_0_0_5_8081_up gauge\nscrape_10_0_0_5_8081_up 1\n" +
+				"# TYPE PollCount_total counter\nPollCount_total 5\n" +
+				"# TYPE requests_total counter\nrequests_total 7\n" +
+				"# TYPE latency histogram\n" +
+				"latency_bucket{le=\"0.1\"} 2\nlatency_bucket{le=\"1\"} 3\nlatency_bucket{le=\"+Inf\"} 4\n" +
+				"latency_sum 3.5\nlatency_count 4\n" +
+				"# TYPE rpc_duration summary\n" +
+				"rpc_duration{quantile=\"0.5\"} 0.2\nrpc_duration{quantile=\"0.99\"} 1.5\n" +
+				"rpc_duration_sum 12\nrpc_duration_count 30\n",
+		},
+		{
+			name: "labels",
+			data: entity.MetricsType{
+				Gauge: entity.GaugeType{
+					`pg_pool_connections{state="idle"}`:                2,
+					`pg_pool_connections{state="acquired"}`:            1,
+					"pg_pool_connections_max":                          10,
+					`node.load{instance="db \"1\"\n",job.name="node"}`: 0.5,
+				},
+				Counter: entity.CounterType{
+					`http_requests_total{method="GET"}`:  3,
+					`http_requests_total{method="POST"}`: 1,
+				},
+				Histogram: entity.HistogramType{
+					`latency{path="/"}`: {Bounds: []float64{1}, Counts: []uint64{1, 1}, Sum: 2.5, Count: 2},
+				},
+			},
+			statusCode: http.StatusOK,
+			body: "# TYPE node_load gauge\nnode_load{instance=\"db \\\"1\\\"\\n\",job_name=\"node\"} 0.5\n" +
+				"# TYPE pg_pool_connections gauge\n" +
+				"pg_pool_connections{state=\"acquired\"} 1\npg_pool_connections{state=\"idle\"} 2\n" +
+				"# TYPE pg_pool_connections_max gauge\npg_pool_connections_max 10\n" +
+				"# TYPE http_requests_total counter\n" +
+				"http_requests_total{method=\"GET\"} 3\nhttp_requests_total{method=\"POST\"} 1\n" +
+				"# TYPE latency histogram\n" +
+				"latency_bucket{path=\"/\",le=\"1\"} 1\nlatency_bucket{path=\"/\",le=\"+Inf\"} 2\n" +
+				"latency_sum{path=\"/\"} 2.5\nlatency_count{path=\"/\"} 2\n",
+		},
+		{
+			name:       "negative",
+			statusCode: http.StatusInternalServerError,
+			err:        errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+
+			getAllDataUsecase := usecase.On("GetAllDataUsecase", mock.Anything).Return(tt.data, tt.err)
+
+			// Act
+			req, err := http.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			if tt.err == nil {
+				assert.Equal(t, tt.body, w.Body.String())
+				assert.Equal(t, expositionContentType, w.Header().Get("Content-Type"))
+			}
+
+			// Unset
+			getAllDataUsecase.Unset()
+		})
+	}
+}
+
+func Test_sanitizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{
+			name: "valid",
+			id:   "go_heap_alloc",
+			want: "go_heap_alloc",
+		},
+		{
+			name: "dots and colons",
+			id:   "scrape.agent:8081.up",
+			want: "scrape_agent_8081_up",
+		},
+		{
+			name: "leading digit",
+			id:   "1m-load",
+			want: "_1m_load",
+		},
+		{
+			name: "empty",
+			want: "_",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := sanitizeName(tt.id)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_splitID(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantName   string
+		wantLabels []string
+	}{
+		{
+			name:       "without labels",
+			id:         "scrape.agent:8081.up",
+			wantName:   "scrape_agent_8081_up",
+			wantLabels: []string{},
+		},
+		{
+			name:       "labels",
+			id:         `http.requests_total{method="GET",path="/a\"b"}`,
+			wantName:   "http_requests_total",
+			wantLabels: []string{`method="GET"`, `path="/a\"b"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			gotName, gotLabels := splitID(tt.id)
+
+			// Assert
+			assert.Equal(t, tt.wantName, gotName)
+			assert.Equal(t, tt.wantLabels, gotLabels)
+		})
+	}
+}
+
 func TestNew(t *testing.T) {
 	mockUsecase := mocks.NewUsecase(t)
 	cfg := mocks.NewCfg(t)
@@ -223,7 +479,7 @@ func TestNew(t *testing.T) {
 		{
 			name: "positive",
 			u:    mockUsecase,
-			want: &Handler{serverUsecase: mockUsecase},
+			want: &Handler{serverUsecase: mockUsecase, remoteWrite: remotewrite.New()},
 		},
 	}
 	for _, tt := range tests {
@@ -313,3 +569,789 @@ func TestHandler_OutputMetric(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_ReceptionRemoteWrite(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+	handler, _ := New(usecase, cfg)
+	router := gin.Default()
+	router.POST("/api/v1/write", handler.ReceptionRemoteWrite)
+
+	// WriteRequest with the series up{job="node"} 1
+	var label, nameLabel, sample, series, request []byte
+	nameLabel = protowire.AppendTag(nameLabel, 1, protowire.BytesType)
+	nameLabel = protowire.AppendString(nameLabel, "__name__")
+	nameLabel = protowire.AppendTag(nameLabel, 2, protowire.BytesType)
+	nameLabel = protowire.AppendString(nameLabel, "up")
+	label = protowire.AppendTag(label, 1, protowire.BytesType)
+	label = protowire.AppendString(label, "job")
+	label = protowire.AppendTag(label, 2, protowire.BytesType)
+	label = protowire.AppendString(label, "node")
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, 0x3ff0000000000000)
+	series = protowire.AppendTag(series, 1, protowire.BytesType)
+	series = protowire.AppendBytes(series, nameLabel)
+	series = protowire.AppendTag(series, 1, protowire.BytesType)
+	series = protowire.AppendBytes(series, label)
+	series = protowire.AppendTag(series, 2, protowire.BytesType)
+	series = protowire.AppendBytes(series, sample)
+	request = protowire.AppendTag(request, 1, protowire.BytesType)
+	request = protowire.AppendBytes(request, series)
+	up := 1.0
+
+	tests := []struct {
+		name       string
+		body       []byte
+		statusCode int
+		save       bool
+		err        error
+	}{
+		{
+			name:       "positive",
+			body:       snappy.Encode(nil, request),
+			statusCode: http.StatusNoContent,
+			save:       true,
+		},
+		{
+			name:       "negative body",
+			body:       request,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "negative usecase",
+			body:       snappy.Encode(nil, request),
+			statusCode: http.StatusInternalServerError,
+			save:       true,
+			err:        errors.New("err"),
+		},
+		{
+			name:       "negative name too long",
+			body:       snappy.Encode(nil, request),
+			statusCode: http.StatusBadRequest,
+			save:       true,
+			err:        fmt.Errorf("%w: up", entity.ErrMetricNameTooLong),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			var saveAllDataBatchUsecase *mock.Call
+			if tt.save {
+				saveAllDataBatchUsecase = usecase.On("SaveAllDataBatchUsecase", mock.Anything, []entity.Metrics{
+					{ID: `up{job="node"}`, MType: "gauge", Value: &up},
+				}).Return(tt.err).Once()
+			}
+
+			// Act
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewBuffer(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Encoding", "snappy")
+			req.Header.Set("Content-Type", "application/x-protobuf")
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+
+			// Unset
+			if saveAllDataBatchUsecase != nil {
+				saveAllDataBatchUsecase.Unset()
+			}
+		})
+	}
+}
+
+func TestHandler_ReceptionOTLP(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+	handler, _ := New(usecase, cfg)
+	router := gin.Default()
+	router.POST("/v1/metrics", handler.ReceptionOTLP)
+
+	const request = `{"resourceMetrics": [{"scopeMetrics": [{"metrics": [
+		{"name": "queue.size", "gauge": {"dataPoints": [{"asDouble": 7}]}},
+		{"name": "errors", "sum": {"aggregationTemporality": 1, "isMonotonic": true, "dataPoints": [{"asInt": "2"}]}}
+	]}]}]}`
+	queueSize := 7.0
+	errorsDelta := int64(2)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		statusCode  int
+		save        bool
+		err         error
+	}{
+		{
+			name:        "positive",
+			contentType: "application/json",
+			body:        request,
+			statusCode:  http.StatusOK,
+			save:        true,
+		},
+		{
+			name:        "negative content type",
+			contentType: "text/plain",
+			body:        request,
+			statusCode:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "negative body",
+			contentType: "application/json",
+			body:        "{",
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "negative usecase",
+			contentType: "application/json",
+			body:        request,
+			statusCode:  http.StatusInternalServerError,
+			save:        true,
+			err:         errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			var calls []*mock.Call
+			if tt.save {
+				calls = append(calls, usecase.On("ReplaceAllDataUsecase", mock.Anything, []entity.Metrics{
+					{ID: "queue.size", MType: "gauge", Value: &queueSize},
+				}).Return(tt.err).Once())
+			}
+			if tt.save && tt.err == nil {
+				calls = append(calls, usecase.On("AddAllDataUsecase", mock.Anything, []entity.Metrics{
+					{ID: "errors", MType: "counter", Delta: &errorsDelta},
+				}).Return(nil).Once())
+			}
+
+			// Act
+			req, err := http.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", tt.contentType)
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+
+			// Unset
+			for _, call := range calls {
+				call.Unset()
+			}
+		})
+	}
+}
+
+func TestHandler_ReceptionInflux(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+	handler, _ := New(usecase, cfg)
+	router := gin.Default()
+	router.POST("/write", handler.ReceptionInflux)
+	usage := 12.5
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		statusCode int
+		save       bool
+		err        error
+	}{
+		{
+			name:       "positive",
+			url:        "/write?db=telegraf&precision=s",
+			body:       "cpu usage_user=12.5 1700000000",
+			statusCode: http.StatusNoContent,
+			save:       true,
+		},
+		{
+			name:       "partial write",
+			url:        "/write",
+			body:       "cpu usage_user=12.5\ncpu",
+			statusCode: http.StatusBadRequest,
+			save:       true,
+		},
+		{
+			name:       "negative precision",
+			url:        "/write?precision=d",
+			body:       "cpu usage_user=12.5",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "negative usecase",
+			url:        "/write",
+			body:       "cpu usage_user=12.5",
+			statusCode: http.StatusInternalServerError,
+			save:       true,
+			err:        errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			var saveAllDataBatchUsecase *mock.Call
+			if tt.save {
+				saveAllDataBatchUsecase = usecase.On("SaveAllDataBatchUsecase", mock.Anything, []entity.Metrics{
+					{ID: "cpu_usage_user", MType: "gauge", Value: &usage},
+				}).Return(tt.err).Once()
+			}
+
+			// Act
+			req, err := http.NewRequest(http.MethodPost, tt.url, bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+
+			// Unset
+			if saveAllDataBatchUsecase != nil {
+				saveAllDataBatchUsecase.Unset()
+			}
+		})
+	}
+}
+
+func TestHandler_ReceptionHostInfo(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+
+	handler, _ := New(usecase, cfg)
+	router := gin.Default()
+	router.POST("/inventory/", handler.ReceptionHostInfo)
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		statusCode int
+		err        error
+		arg        entity.HostInfo
+	}{
+		{
+			name:       "check content type",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "positive",
+			statusCode: http.StatusOK,
+			header: http.Header{
+				"Content-Type": {"application/json"},
+			},
+			arg: entity.HostInfo{Hostname: "web-1"},
+		},
+		{
+			name:       "negative hostname",
+			statusCode: http.StatusBadRequest,
+			header: http.Header{
+				"Content-Type": {"application/json"},
+			},
+			err: entity.ErrHostnameNotSet,
+		},
+		{
+			name:       "negative storage",
+			statusCode: http.StatusInternalServerError,
+			header: http.Header{
+				"Content-Type": {"application/json"},
+			},
+			arg: entity.HostInfo{Hostname: "web-1"},
+			err: errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			arg, _ := json.Marshal(tt.arg)
+			w := httptest.NewRecorder()
+
+			saveHostInfoUsecase := usecase.On("SaveHostInfoUsecase", mock.Anything, tt.arg).Return(tt.err).Maybe()
+
+			// Act
+			req, err := http.NewRequest(http.MethodPost, "/inventory/", bytes.NewBuffer(arg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header = tt.header
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+
+			// Unset
+			saveHostInfoUsecase.Unset()
+		})
+	}
+}
+
+func TestHandler_OutputHostsJSON(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+	handler, _ := New(usecase, cfg)
+	router := gin.Default()
+	router.GET("/api/hosts", handler.OutputHostsJSON)
+
+	tests := []struct {
+		name       string
+		hosts      []entity.HostInfo
+		statusCode int
+		err        error
+	}{
+		{
+			name:       "positive",
+			hosts:      []entity.HostInfo{{Hostname: "web-1"}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "negative",
+			statusCode: http.StatusInternalServerError,
+			err:        errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+
+			getAllHostInfoUsecase := usecase.On("GetAllHostInfoUsecase", mock.Anything).Return(tt.hosts, tt.err)
+
+			// Act
+			req, err := http.NewRequest(http.MethodGet, "/api/hosts", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			if tt.err == nil {
+				var hosts []entity.HostInfo
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &hosts))
+				assert.Equal(t, tt.hosts, hosts)
+			}
+
+			// Unset
+			getAllHostInfoUsecase.Unset()
+		})
+	}
+}
+
+func TestHandler_ReceptionMetrics_registerAgent(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+
+	handler, _ := New(usecase, cfg)
+	router := gin.Default()
+	router.POST("/updates/", handler.ReceptionMetrics)
+
+	value := 1.5
+	tests := []struct {
+		name       string
+		header     http.Header
+		agent      *entity.Agent
+		agentErr   error
+		version    string
+		statusCode int
+	}{
+		{
+			name: "positive",
+			header: http.Header{
+				"Content-Type":              {"application/json"},
+				entity.AgentIDHeader:        {"c0ffee"},
+				entity.AgentVersionHeader:   {"1.0"},
+				entity.ReportIntervalHeader: {"10"},
+			},
+			agent:      &entity.Agent{ID: "c0ffee", Address: "192.0.2.1", Version: "1.0", ReportInterval: 10, Metrics: 1},
+			version:    "5d41402abc4b",
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "registry error does not fail the request",
+			header: http.Header{
+				"Content-Type":       {"application/json"},
+				entity.AgentIDHeader: {"c0ffee"},
+			},
+			agent:      &entity.Agent{ID: "c0ffee", Address: "192.0.2.1", Metrics: 1},
+			agentErr:   errors.New("err"),
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "without agent id",
+			header: http.Header{
+				"Content-Type": {"application/json"},
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			args, _ := json.Marshal([]entity.Metrics{{ID: "Alloc", MType: "gauge", Value: &value}})
+			w := httptest.NewRecorder()
+
+			saveAllDataBatchUsecase := usecase.On("SaveAllDataBatchUsecase", mock.Anything, mock.Anything).Return(nil)
+			var saveAgentUsecase *mock.Call
+			if tt.agent != nil {
+				saveAgentUsecase = usecase.On("SaveAgentUsecase", mock.Anything, *tt.agent).Return(tt.agentErr).Once()
+			}
+			configErr := entity.ErrAgentConfigNotFound
+			if tt.version != "" {
+				configErr = nil
+			}
+			getAgentConfigUsecase := usecase.On("GetAgentConfigUsecase", mock.Anything, "c0ffee", "").Return(entity.AgentConfig{Version: tt.version}, configErr).Maybe()
+
+			// Act
+			req, err := http.NewRequest(http.MethodPost, "/updates/", bytes.NewBuffer(args))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header = tt.header
+			req.RemoteAddr = "192.0.2.1:4242"
+			router.ServeHTTP(w, req)
+
+			// Assert
+			var response entity.UpdatesResponse
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.version, response.ConfigVersion)
+
+			// Unset
+			saveAllDataBatchUsecase.Unset()
+			getAgentConfigUsecase.Unset()
+			if saveAgentUsecase != nil {
+				saveAgentUsecase.Unset()
+			}
+		})
+	}
+}
+
+func TestHandler_OutputTargetsJSON(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+	scrapeTargets := mocks.NewScrapeTargets(t)
+	scrapeTargets.On("GetTargets").Return([]entity.ScrapeTarget{{Address: "10.0.0.5:8081", Health: entity.TargetUp}})
+
+	tests := []struct {
+		name    string
+		targets []any
+		want    []entity.ScrapeTarget
+	}{
+		{
+			name:    "positive",
+			targets: []any{scrapeTargets},
+			want:    []entity.ScrapeTarget{{Address: "10.0.0.5:8081", Health: entity.TargetUp}},
+		},
+		{
+			name: "pull mode is disabled",
+			want: []entity.ScrapeTarget{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			handler, _ := New(usecase, cfg, tt.targets...)
+			router := gin.Default()
+			router.GET("/api/targets", handler.OutputTargetsJSON)
+
+			// Act
+			req, err := http.NewRequest(http.MethodGet, "/api/targets", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			var targets []entity.ScrapeTarget
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &targets))
+			assert.Equal(t, tt.want, targets)
+		})
+	}
+}
+
+func TestHandler_OutputHistory(t *testing.T) {
+	from := time.Unix(1700000000, 0)
+	to := time.Unix(1700003600, 0)
+	last := 0.5
+	points := []entity.HistoryPoint{{Time: from, Avg: &last, Min: &last, Max: &last, Last: &last}}
+
+	tests := []struct {
+		name       string
+		url        string
+		metricName string
+		step       time.Duration
+		err        error
+		statusCode int
+	}{
+		{
+			name:       "positive",
+			url:        "/api/history/gauge/load?from=1700000000&to=2023-11-14T23:13:20Z&step=30s",
+			metricName: "load",
+			step:       30 * time.Second,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "positive name with slashes",
+			url:        `/api/history/gauge/disk_used{path="/mnt/data"}?from=1700000000&to=1700003600&step=60`,
+			metricName: `disk_used{path="/mnt/data"}`,
+			step:       time.Minute,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "negative step",
+			url:        "/api/history/gauge/load?step=soon",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "negative range",
+			url:        "/api/history/gauge/load?from=1700000000&to=1700003600",
+			metricName: "load",
+			step:       time.Minute,
+			err:        entity.ErrInvalidHistoryRange,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "negative storage",
+			url:        "/api/history/gauge/load?from=1700000000&to=1700003600",
+			metricName: "load",
+			step:       time.Minute,
+			err:        errors.New("err"),
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			usecase := mocks.NewUsecase(t)
+			cfg := mocks.NewCfg(t)
+			cfg.On("UseCryptoKey").Return(false)
+			cfg.On("GetKey").Return("")
+			handler, _ := New(usecase, cfg)
+			router := gin.Default()
+			router.GET("/api/history/:metricType/*metricName", handler.OutputHistory)
+			if tt.metricName != "" {
+				usecase.On("GetHistoryUsecase", mock.Anything, entity.GaugeMetric, tt.metricName, mock.Anything, mock.Anything, tt.step).Return(points, tt.err)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			req, err := http.NewRequest(http.MethodGet, tt.url, http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			if tt.statusCode == http.StatusOK {
+				var history entity.History
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+				assert.Equal(t, tt.metricName, history.ID)
+				assert.True(t, from.Equal(history.From))
+				assert.True(t, to.Equal(history.To))
+				assert.Len(t, history.Points, 1)
+			}
+		})
+	}
+}
+
+func TestHandler_OutputAgentsJSON(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+	handler, _ := New(usecase, cfg)
+	router := gin.Default()
+	router.GET("/api/agents", handler.OutputAgentsJSON)
+
+	tests := []struct {
+		name       string
+		agents     []entity.Agent
+		statusCode int
+		err        error
+	}{
+		{
+			name:       "positive",
+			agents:     []entity.Agent{{ID: "c0ffee", Status: entity.AgentOnline}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "negative",
+			statusCode: http.StatusInternalServerError,
+			err:        errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+
+			getAllAgentsUsecase := usecase.On("GetAllAgentsUsecase", mock.Anything).Return(tt.agents, tt.err)
+
+			// Act
+			req, err := http.NewRequest(http.MethodGet, "/api/agents", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			if tt.err == nil {
+				var agents []entity.Agent
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &agents))
+				assert.Equal(t, tt.agents, agents)
+			}
+
+			// Unset
+			getAllAgentsUsecase.Unset()
+		})
+	}
+}
+
+func TestHandler_OutputAgentConfig(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+	handler, _ := New(usecase, cfg)
+	router := gin.Default()
+	router.GET("/agent-config/", handler.OutputAgentConfig)
+
+	tests := []struct {
+		name        string
+		agentConfig entity.AgentConfig
+		statusCode  int
+		err         error
+	}{
+		{
+			name:        "positive",
+			agentConfig: entity.AgentConfig{Version: "5d41402abc4b", PollInterval: 5, Collectors: []string{"psi"}},
+			statusCode:  http.StatusOK,
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			err:        entity.ErrAgentConfigNotFound,
+		},
+		{
+			name:       "negative",
+			statusCode: http.StatusInternalServerError,
+			err:        errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+
+			getAgentConfigUsecase := usecase.On("GetAgentConfigUsecase", mock.Anything, "c0ffee", "db").Return(tt.agentConfig, tt.err)
+
+			// Act
+			req, err := http.NewRequest(http.MethodGet, "/agent-config/", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set(entity.AgentIDHeader, "c0ffee")
+			req.Header.Set(entity.AgentGroupHeader, "db")
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			if tt.err == nil {
+				var agentConfig entity.AgentConfig
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &agentConfig))
+				assert.Equal(t, tt.agentConfig, agentConfig)
+			}
+
+			// Unset
+			getAgentConfigUsecase.Unset()
+		})
+	}
+}
+
+func TestHandler_ReceptionAgentConfig(t *testing.T) {
+	usecase := mocks.NewUsecase(t)
+	cfg := mocks.NewCfg(t)
+	cfg.On("UseCryptoKey").Return(false)
+	cfg.On("GetKey").Return("")
+	handler, _ := New(usecase, cfg)
+	router := gin.Default()
+	router.POST("/agent-config/", handler.ReceptionAgentConfig)
+
+	agentConfig := entity.AgentConfig{Version: "5d41402abc4b", PollInterval: 2, ReportInterval: 10, Collectors: []string{"psi"}}
+	tests := []struct {
+		name       string
+		agentID    string
+		statusCode int
+		err        error
+	}{
+		{
+			name:       "positive",
+			agentID:    "c0ffee",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "negative agent id",
+			statusCode: http.StatusBadRequest,
+			err:        entity.ErrAgentIDNotSet,
+		},
+		{
+			name:       "negative storage",
+			agentID:    "c0ffee",
+			statusCode: http.StatusInternalServerError,
+			err:        errors.New("err"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			body, _ := json.Marshal(agentConfig)
+			w := httptest.NewRecorder()
+
+			saveAgentUsecase := usecase.On("SaveAgentUsecase", mock.Anything, mock.Anything).Return(nil).Maybe()
+			saveAgentConfigUsecase := usecase.On("SaveAgentConfigUsecase", mock.Anything, tt.agentID, agentConfig).Return(tt.err)
+
+			// Act
+			req, err := http.NewRequest(http.MethodPost, "/agent-config/", bytes.NewBuffer(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if tt.agentID != "" {
+				req.Header.Set(entity.AgentIDHeader, tt.agentID)
+			}
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+
+			// Unset
+			saveAgentUsecase.Unset()
+			saveAgentConfigUsecase.Unset()
+		})
+	}
+}
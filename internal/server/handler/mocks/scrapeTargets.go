@@ -0,0 +1,48 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ScrapeTargets is an autogenerated mock type for the scrapeTargets type
+type ScrapeTargets struct {
+	mock.Mock
+}
+
+// GetTargets provides a mock function with given fields:
+func (_m *ScrapeTargets) GetTargets() []entity.ScrapeTarget {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTargets")
+	}
+
+	var r0 []entity.ScrapeTarget
+	if rf, ok := ret.Get(0).(func() []entity.ScrapeTarget); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.ScrapeTarget)
+		}
+	}
+
+	return r0
+}
+
+// NewScrapeTargets creates a new instance of ScrapeTargets. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewScrapeTargets(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ScrapeTargets {
+	mock := &ScrapeTargets{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -8,6 +8,8 @@ import (
 	entity "github.com/korovindenis/go-pc-metrics/internal/domain/entity"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Usecase is an autogenerated mock type for the usecase type
@@ -15,6 +17,82 @@ type Usecase struct {
 	mock.Mock
 }
 
+// AddAllDataUsecase provides a mock function with given fields: ctx, metrics
+func (_m *Usecase) AddAllDataUsecase(ctx context.Context, metrics []entity.Metrics) error {
+	ret := _m.Called(ctx, metrics)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAllDataUsecase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.Metrics) error); ok {
+		r0 = rf(ctx, metrics)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAgentConfigUsecase provides a mock function with given fields: ctx, agentID, group
+func (_m *Usecase) GetAgentConfigUsecase(ctx context.Context, agentID string, group string) (entity.AgentConfig, error) {
+	ret := _m.Called(ctx, agentID, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAgentConfigUsecase")
+	}
+
+	var r0 entity.AgentConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (entity.AgentConfig, error)); ok {
+		return rf(ctx, agentID, group)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) entity.AgentConfig); ok {
+		r0 = rf(ctx, agentID, group)
+	} else {
+		r0 = ret.Get(0).(entity.AgentConfig)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, agentID, group)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllAgentsUsecase provides a mock function with given fields: ctx
+func (_m *Usecase) GetAllAgentsUsecase(ctx context.Context) ([]entity.Agent, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllAgentsUsecase")
+	}
+
+	var r0 []entity.Agent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]entity.Agent, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.Agent); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Agent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllDataUsecase provides a mock function with given fields: ctx
 func (_m *Usecase) GetAllDataUsecase(ctx context.Context) (entity.MetricsType, error) {
 	ret := _m.Called(ctx)
@@ -43,6 +121,36 @@ func (_m *Usecase) GetAllDataUsecase(ctx context.Context) (entity.MetricsType, e
 	return r0, r1
 }
 
+// GetAllHostInfoUsecase provides a mock function with given fields: ctx
+func (_m *Usecase) GetAllHostInfoUsecase(ctx context.Context) ([]entity.HostInfo, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllHostInfoUsecase")
+	}
+
+	var r0 []entity.HostInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]entity.HostInfo, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.HostInfo); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.HostInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCounterUsecase provides a mock function with given fields: ctx, counterName
 func (_m *Usecase) GetCounterUsecase(ctx context.Context, counterName string) (int64, error) {
 	ret := _m.Called(ctx, counterName)
@@ -99,6 +207,92 @@ func (_m *Usecase) GetGaugeUsecase(ctx context.Context, gaugeName string) (float
 	return r0, r1
 }
 
+// GetHistogramUsecase provides a mock function with given fields: ctx, histogramName
+func (_m *Usecase) GetHistogramUsecase(ctx context.Context, histogramName string) (entity.HistogramValue, error) {
+	ret := _m.Called(ctx, histogramName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHistogramUsecase")
+	}
+
+	var r0 entity.HistogramValue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (entity.HistogramValue, error)); ok {
+		return rf(ctx, histogramName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) entity.HistogramValue); ok {
+		r0 = rf(ctx, histogramName)
+	} else {
+		r0 = ret.Get(0).(entity.HistogramValue)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, histogramName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHistoryUsecase provides a mock function with given fields: ctx, metricType, metricName, from, to, step
+func (_m *Usecase) GetHistoryUsecase(ctx context.Context, metricType string, metricName string, from time.Time, to time.Time, step time.Duration) ([]entity.HistoryPoint, error) {
+	ret := _m.Called(ctx, metricType, metricName, from, to, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHistoryUsecase")
+	}
+
+	var r0 []entity.HistoryPoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, time.Time, time.Duration) ([]entity.HistoryPoint, error)); ok {
+		return rf(ctx, metricType, metricName, from, to, step)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, time.Time, time.Duration) []entity.HistoryPoint); ok {
+		r0 = rf(ctx, metricType, metricName, from, to, step)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.HistoryPoint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time, time.Time, time.Duration) error); ok {
+		r1 = rf(ctx, metricType, metricName, from, to, step)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSummaryUsecase provides a mock function with given fields: ctx, summaryName
+func (_m *Usecase) GetSummaryUsecase(ctx context.Context, summaryName string) (entity.SummaryValue, error) {
+	ret := _m.Called(ctx, summaryName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSummaryUsecase")
+	}
+
+	var r0 entity.SummaryValue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (entity.SummaryValue, error)); ok {
+		return rf(ctx, summaryName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) entity.SummaryValue); ok {
+		r0 = rf(ctx, summaryName)
+	} else {
+		r0 = ret.Get(0).(entity.SummaryValue)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, summaryName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Ping provides a mock function with given fields: ctx
 func (_m *Usecase) Ping(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -117,6 +311,60 @@ func (_m *Usecase) Ping(ctx context.Context) error {
 	return r0
 }
 
+// ReplaceAllDataUsecase provides a mock function with given fields: ctx, metrics
+func (_m *Usecase) ReplaceAllDataUsecase(ctx context.Context, metrics []entity.Metrics) error {
+	ret := _m.Called(ctx, metrics)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceAllDataUsecase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.Metrics) error); ok {
+		r0 = rf(ctx, metrics)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveAgentConfigUsecase provides a mock function with given fields: ctx, agentID, agentConfig
+func (_m *Usecase) SaveAgentConfigUsecase(ctx context.Context, agentID string, agentConfig entity.AgentConfig) error {
+	ret := _m.Called(ctx, agentID, agentConfig)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAgentConfigUsecase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.AgentConfig) error); ok {
+		r0 = rf(ctx, agentID, agentConfig)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveAgentUsecase provides a mock function with given fields: ctx, agent
+func (_m *Usecase) SaveAgentUsecase(ctx context.Context, agent entity.Agent) error {
+	ret := _m.Called(ctx, agent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAgentUsecase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Agent) error); ok {
+		r0 = rf(ctx, agent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveAllDataBatchUsecase provides a mock function with given fields: ctx, metrics
 func (_m *Usecase) SaveAllDataBatchUsecase(ctx context.Context, metrics []entity.Metrics) error {
 	ret := _m.Called(ctx, metrics)
@@ -189,6 +437,60 @@ func (_m *Usecase) SaveGaugeUsecase(ctx context.Context, gaugeName string, gauge
 	return r0
 }
 
+// SaveHistogramUsecase provides a mock function with given fields: ctx, histogramName, histogramValue
+func (_m *Usecase) SaveHistogramUsecase(ctx context.Context, histogramName string, histogramValue entity.HistogramValue) error {
+	ret := _m.Called(ctx, histogramName, histogramValue)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveHistogramUsecase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.HistogramValue) error); ok {
+		r0 = rf(ctx, histogramName, histogramValue)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveHostInfoUsecase provides a mock function with given fields: ctx, hostInfo
+func (_m *Usecase) SaveHostInfoUsecase(ctx context.Context, hostInfo entity.HostInfo) error {
+	ret := _m.Called(ctx, hostInfo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveHostInfoUsecase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.HostInfo) error); ok {
+		r0 = rf(ctx, hostInfo)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveSummaryUsecase provides a mock function with given fields: ctx, summaryName, summaryValue
+func (_m *Usecase) SaveSummaryUsecase(ctx context.Context, summaryName string, summaryValue entity.SummaryValue) error {
+	ret := _m.Called(ctx, summaryName, summaryValue)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSummaryUsecase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.SummaryValue) error); ok {
+		r0 = rf(ctx, summaryName, summaryValue)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewUsecase creates a new instance of Usecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUsecase(t interface {
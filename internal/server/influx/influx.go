@@ -0,0 +1,246 @@
+// InfluxDB v1 line protocol
+package influx
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+)
+
+const counterSuffix = "_total"
+
+var (
+	ErrInvalidPrecision = errors.New("invalid precision")
+
+	errMissingMeasurement = errors.New("missing measurement")
+	errMissingFields      = errors.New("missing fields")
+	errInvalidTag         = errors.New("invalid tag")
+	errInvalidField       = errors.New("invalid field")
+	errInvalidFieldValue  = errors.New("invalid field value")
+	errInvalidTimestamp   = errors.New("invalid timestamp")
+)
+
+// nanoseconds in a unit of the precision parameter
+var precisions = map[string]int64{
+	"":   1,
+	"n":  1,
+	"ns": 1,
+	"u":  int64(time.Microsecond),
+	"us": int64(time.Microsecond),
+	"ms": int64(time.Millisecond),
+	"s":  int64(time.Second),
+	"m":  int64(time.Minute),
+	"h":  int64(time.Hour),
+}
+
+// LineError - line that can't be parsed, lines are numbered from 1
+type LineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type point struct {
+	metric entity.Metrics
+	time   int64
+}
+
+// Parse converts lines to metrics named measurement_field with tags as labels.
+// Integer fields and fields with the _total suffix become counters, floats and booleans gauges,
+// string fields are skipped. The latest point of every series is kept,
+// lines without a timestamp have the time now
+func Parse(body []byte, precision string, now time.Time) ([]entity.Metrics, []LineError, error) {
+	multiplier, ok := precisions[precision]
+	if !ok {
+		return nil, nil, ErrInvalidPrecision
+	}
+
+	var points []point
+	latest := make(map[string]int)
+	var lineErrors []LineError
+
+	for i, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		linePoints, err := parseLine(line, multiplier, now)
+		if err != nil {
+			lineErrors = append(lineErrors, LineError{Line: i + 1, Error: err.Error()})
+			continue
+		}
+		for _, p := range linePoints {
+			if index, ok := latest[p.metric.ID]; ok {
+				if p.time >= points[index].time {
+					points[index] = p
+				}
+				continue
+			}
+			latest[p.metric.ID] = len(points)
+			points = append(points, p)
+		}
+	}
+
+	metrics := make([]entity.Metrics, 0, len(points))
+	for _, p := range points {
+		metrics = append(metrics, p.metric)
+	}
+	return metrics, lineErrors, nil
+}
+
+// parseLine parses measurement[,tag=value...] field=value[,field=value...] [timestamp]
+func parseLine(line string, multiplier int64, now time.Time) ([]point, error) {
+	var sections []string
+	for _, section := range split(line, ' ', true) {
+		if section != "" {
+			sections = append(sections, section)
+		}
+	}
+	if len(sections) < 2 {
+		return nil, errMissingFields
+	}
+	if len(sections) > 3 {
+		return nil, errInvalidTimestamp
+	}
+
+	timestamp := now.UnixNano()
+	if len(sections) == 3 {
+		ts, err := strconv.ParseInt(sections[2], 10, 64)
+		if err != nil {
+			return nil, errInvalidTimestamp
+		}
+		timestamp = ts * multiplier
+	}
+
+	key := split(sections[0], ',', false)
+	measurement := unescape(key[0])
+	if measurement == "" {
+		return nil, errMissingMeasurement
+	}
+	tags := make(map[string]string, len(key)-1)
+	for _, tag := range key[1:] {
+		i := index(tag, '=')
+		if i <= 0 || i == len(tag)-1 {
+			return nil, errInvalidTag
+		}
+		tags[unescape(tag[:i])] = unescape(tag[i+1:])
+	}
+
+	var points []point
+	for _, field := range split(sections[1], ',', true) {
+		i := index(field, '=')
+		if i <= 0 || i == len(field)-1 {
+			return nil, errInvalidField
+		}
+		name := measurement + "_" + unescape(field[:i])
+
+		metric, ok, err := parseValue(name, field[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		metric.ID = entity.SeriesID(name, tags)
+		points = append(points, point{metric: metric, time: timestamp})
+	}
+	return points, nil
+}
+
+// parseValue returns false for string values
+func parseValue(name, value string) (entity.Metrics, bool, error) {
+	if strings.HasPrefix(value, `"`) {
+		if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+			return entity.Metrics{}, false, errInvalidFieldValue
+		}
+		return entity.Metrics{}, false, nil
+	}
+
+	switch value {
+	case "t", "T", "true", "True", "TRUE":
+		one := 1.0
+		return entity.Metrics{MType: entity.GaugeMetric, Value: &one}, true, nil
+	case "f", "F", "false", "False", "FALSE":
+		zero := 0.0
+		return entity.Metrics{MType: entity.GaugeMetric, Value: &zero}, true, nil
+	}
+
+	switch value[len(value)-1] {
+	case 'i':
+		delta, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+		if err != nil {
+			return entity.Metrics{}, false, errInvalidFieldValue
+		}
+		return entity.Metrics{MType: entity.CounterMetric, Delta: &delta}, true, nil
+	case 'u':
+		unsigned, err := strconv.ParseUint(value[:len(value)-1], 10, 63)
+		if err != nil {
+			return entity.Metrics{}, false, errInvalidFieldValue
+		}
+		delta := int64(unsigned)
+		return entity.Metrics{MType: entity.CounterMetric, Delta: &delta}, true, nil
+	}
+
+	gaugeValue, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(gaugeValue) || math.IsInf(gaugeValue, 0) {
+		return entity.Metrics{}, false, errInvalidFieldValue
+	}
+	if strings.HasSuffix(name, counterSuffix) {
+		delta := int64(math.Round(gaugeValue))
+		return entity.Metrics{MType: entity.CounterMetric, Delta: &delta}, true, nil
+	}
+	return entity.Metrics{MType: entity.GaugeMetric, Value: &gaugeValue}, true, nil
+}
+
+// split splits s by sep that is not escaped with a backslash,
+// with quotes sep inside double quoted strings is kept
+func split(s string, sep byte, quotes bool) []string {
+	var parts []string
+	start := 0
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case quotes && s[i] == '"':
+			inQuotes = !inQuotes
+		case s[i] == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// index returns the position of the first c that is not escaped or -1
+func index(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape removes backslashes before commas, spaces, equal signs, quotes and backslashes
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`, ="\`, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
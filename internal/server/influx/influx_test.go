@@ -0,0 +1,96 @@
+// InfluxDB v1 line protocol
+
+package influx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Unix(100, 0)
+	usage := 12.5
+	newerUsage := 13.5
+	bytesRecv := int64(1024)
+	requests := int64(8)
+	up := 1.0
+
+	tests := []struct {
+		name       string
+		body       string
+		precision  string
+		want       []entity.Metrics
+		lineErrors []LineError
+		err        error
+	}{
+		{
+			name: "positive",
+			body: "# telegraf\n" +
+				"cpu,host=db-1,cpu=cpu-total usage_user=12.5,up=true,version=\"1.2 beta\" 1000000000\n" +
+				"net,host=db-1 bytes_recv=1024i\n" +
+				"nginx requests_total=7.6\r\n",
+			want: []entity.Metrics{
+				{ID: `cpu_usage_user{cpu="cpu-total",host="db-1"}`, MType: "gauge", Value: &usage},
+				{ID: `cpu_up{cpu="cpu-total",host="db-1"}`, MType: "gauge", Value: &up},
+				{ID: `net_bytes_recv{host="db-1"}`, MType: "counter", Delta: &bytesRecv},
+				{ID: "nginx_requests_total", MType: "counter", Delta: &requests},
+			},
+		},
+		{
+			name:      "latest point with precision",
+			precision: "s",
+			body: "cpu usage_user=13.5 2\n" +
+				"cpu usage_user=12.5 1\n",
+			want: []entity.Metrics{
+				{ID: "cpu_usage_user", MType: "gauge", Value: &newerUsage},
+			},
+		},
+		{
+			name: "escaping",
+			body: `disk\ io,path=/mnt/my\ disk,dev\=x=sda used=12.5`,
+			want: []entity.Metrics{
+				{ID: `disk io_used{dev=x="sda",path="/mnt/my disk"}`, MType: "gauge", Value: &usage},
+			},
+		},
+		{
+			name: "line errors",
+			body: "cpu\n" +
+				"cpu usage_user=abc\n" +
+				"cpu,host usage_user=1\n" +
+				"cpu usage_user=1 soon\n" +
+				"cpu usage_user=12.5\n",
+			want: []entity.Metrics{
+				{ID: "cpu_usage_user", MType: "gauge", Value: &usage},
+			},
+			lineErrors: []LineError{
+				{Line: 1, Error: errMissingFields.Error()},
+				{Line: 2, Error: errInvalidFieldValue.Error()},
+				{Line: 3, Error: errInvalidTag.Error()},
+				{Line: 4, Error: errInvalidTimestamp.Error()},
+			},
+		},
+		{
+			name:      "negative precision",
+			body:      "cpu usage_user=1",
+			precision: "d",
+			err:       ErrInvalidPrecision,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, lineErrors, err := Parse([]byte(tt.body), tt.precision, now)
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+			if tt.err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.lineErrors, lineErrors)
+		})
+	}
+}
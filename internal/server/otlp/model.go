@@ -0,0 +1,147 @@
+package otlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// aggregation temporality of sums and histograms
+const (
+	temporalityDelta      = 1
+	temporalityCumulative = 2
+)
+
+// the subset of ExportMetricsServiceRequest used by the server,
+// json tags follow the OTLP/JSON mapping
+type exportRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeMetrics struct {
+	Metrics []metric `json:"metrics"`
+}
+
+type metric struct {
+	Name      string     `json:"name"`
+	Gauge     *gauge     `json:"gauge"`
+	Sum       *sum       `json:"sum"`
+	Histogram *histogram `json:"histogram"`
+}
+
+type gauge struct {
+	DataPoints []numberDataPoint `json:"dataPoints"`
+}
+
+type sum struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality temporality       `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+type histogram struct {
+	DataPoints             []histogramDataPoint `json:"dataPoints"`
+	AggregationTemporality temporality          `json:"aggregationTemporality"`
+}
+
+type numberDataPoint struct {
+	Attributes   []keyValue `json:"attributes"`
+	TimeUnixNano jsonUint64 `json:"timeUnixNano"`
+	AsDouble     *float64   `json:"asDouble"`
+	AsInt        *jsonInt64 `json:"asInt"`
+}
+
+type histogramDataPoint struct {
+	Attributes     []keyValue   `json:"attributes"`
+	TimeUnixNano   jsonUint64   `json:"timeUnixNano"`
+	Count          jsonUint64   `json:"count"`
+	Sum            *float64     `json:"sum"`
+	BucketCounts   []jsonUint64 `json:"bucketCounts"`
+	ExplicitBounds []float64    `json:"explicitBounds"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+// anyValue keeps scalar values only, others can't be labels
+type anyValue struct {
+	StringValue *string    `json:"stringValue"`
+	BoolValue   *bool      `json:"boolValue"`
+	IntValue    *jsonInt64 `json:"intValue"`
+	DoubleValue *float64   `json:"doubleValue"`
+}
+
+// label value of the attribute, false for values that are not scalar
+func (v anyValue) label() (string, bool) {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue, true
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue), true
+	case v.IntValue != nil:
+		return strconv.FormatInt(int64(*v.IntValue), 10), true
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'g', -1, 64), true
+	}
+	return "", false
+}
+
+// jsonInt64 is a 64-bit integer sent as a string or a number in OTLP/JSON
+type jsonInt64 int64
+
+func (i *jsonInt64) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseInt(string(bytes.Trim(data, `"`)), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = jsonInt64(v)
+	return nil
+}
+
+// jsonUint64 is a 64-bit unsigned integer sent as a string or a number in OTLP/JSON
+type jsonUint64 uint64
+
+func (i *jsonUint64) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseUint(string(bytes.Trim(data, `"`)), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = jsonUint64(v)
+	return nil
+}
+
+// temporality is sent as a number or the enum name in OTLP/JSON
+type temporality int
+
+func (t *temporality) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var v int
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*t = temporality(v)
+		return nil
+	}
+
+	switch name {
+	case "AGGREGATION_TEMPORALITY_DELTA":
+		*t = temporalityDelta
+	case "AGGREGATION_TEMPORALITY_CUMULATIVE":
+		*t = temporalityCumulative
+	default:
+		*t = 0
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// OpenTelemetry OTLP/HTTP metrics requests
+package otlp
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+)
+
+// content types of OTLP/HTTP
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// Decode converts an ExportMetricsServiceRequest in protobuf or JSON to metrics.
+// Values replace the stored metrics, the latest point of every series is kept,
+// increments (points of delta temporality) are added to the stored metrics.
+// Cumulative histograms are values too, so they replace the stored histograms
+func Decode(body []byte, contentType string) (values, increments []entity.Metrics, err error) {
+	var request exportRequest
+	if strings.HasPrefix(contentType, ContentTypeJSON) {
+		err = json.Unmarshal(body, &request)
+	} else {
+		request, err = unmarshalRequest(body)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := newConverter()
+	for _, rm := range request.ResourceMetrics {
+		resourceLabels := labels(nil, rm.Resource.Attributes)
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				c.convert(m, resourceLabels)
+			}
+		}
+	}
+
+	return c.values, c.increments, nil
+}
+
+type converter struct {
+	values     []entity.Metrics
+	increments []entity.Metrics
+	// index and time of the point in values by series
+	latest map[string]latestPoint
+}
+
+type latestPoint struct {
+	index int
+	time  uint64
+}
+
+func newConverter() *converter {
+	return &converter{
+		latest: make(map[string]latestPoint),
+	}
+}
+
+// convert maps gauges to gauges, monotonic sums to counters, other sums to gauges
+// and histograms with explicit buckets to histograms
+func (c *converter) convert(m metric, resourceLabels map[string]string) {
+	switch {
+	case m.Gauge != nil:
+		for _, point := range m.Gauge.DataPoints {
+			c.addNumber(m.Name, labels(resourceLabels, point.Attributes), entity.GaugeMetric, point.value(), uint64(point.TimeUnixNano), false)
+		}
+	case m.Sum != nil:
+		mType := entity.GaugeMetric
+		if m.Sum.IsMonotonic {
+			mType = entity.CounterMetric
+		}
+		isDelta := m.Sum.AggregationTemporality == temporalityDelta
+		for _, point := range m.Sum.DataPoints {
+			c.addNumber(m.Name, labels(resourceLabels, point.Attributes), mType, point.value(), uint64(point.TimeUnixNano), isDelta)
+		}
+	case m.Histogram != nil:
+		isDelta := m.Histogram.AggregationTemporality == temporalityDelta
+		for _, point := range m.Histogram.DataPoints {
+			histogramValue, ok := point.value()
+			if !ok || m.Name == "" {
+				continue
+			}
+			metric := entity.Metrics{
+				ID:        entity.SeriesID(m.Name, labels(resourceLabels, point.Attributes)),
+				MType:     entity.HistogramMetric,
+				Histogram: &histogramValue,
+			}
+			c.add(metric, uint64(point.TimeUnixNano), isDelta)
+		}
+	}
+}
+
+func (c *converter) addNumber(name string, seriesLabels map[string]string, mType string, value float64, pointTime uint64, isDelta bool) {
+	if name == "" || math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+
+	metric := entity.Metrics{ID: entity.SeriesID(name, seriesLabels), MType: mType}
+	if mType == entity.CounterMetric {
+		delta := int64(math.Round(value))
+		metric.Delta = &delta
+	} else {
+		metric.Value = &value
+	}
+	c.add(metric, pointTime, isDelta)
+}
+
+func (c *converter) add(metric entity.Metrics, pointTime uint64, isDelta bool) {
+	if isDelta {
+		c.increments = append(c.increments, metric)
+		return
+	}
+	if prev, ok := c.latest[metric.ID]; ok {
+		if pointTime >= prev.time {
+			c.values[prev.index] = metric
+			c.latest[metric.ID] = latestPoint{index: prev.index, time: pointTime}
+		}
+		return
+	}
+	c.latest[metric.ID] = latestPoint{index: len(c.values), time: pointTime}
+	c.values = append(c.values, metric)
+}
+
+// value converts the point to a histogram, points without buckets or with a wrong count are skipped
+func (p histogramDataPoint) value() (entity.HistogramValue, bool) {
+	histogramValue := entity.HistogramValue{
+		Bounds: p.ExplicitBounds,
+		Counts: make([]uint64, len(p.BucketCounts)),
+		Count:  uint64(p.Count),
+	}
+	for i, count := range p.BucketCounts {
+		histogramValue.Counts[i] = uint64(count)
+	}
+	if p.Sum != nil {
+		if math.IsNaN(*p.Sum) || math.IsInf(*p.Sum, 0) {
+			return entity.HistogramValue{}, false
+		}
+		histogramValue.Sum = *p.Sum
+	}
+	if histogramValue.Validate() != nil {
+		return entity.HistogramValue{}, false
+	}
+	return histogramValue, true
+}
+
+func (p numberDataPoint) value() float64 {
+	if p.AsInt != nil {
+		return float64(*p.AsInt)
+	}
+	if p.AsDouble != nil {
+		return *p.AsDouble
+	}
+	return math.NaN()
+}
+
+// labels copies base and adds scalar attributes
+func labels(base map[string]string, attributes []keyValue) map[string]string {
+	result := make(map[string]string, len(base)+len(attributes))
+	for key, value := range base {
+		result[key] = value
+	}
+	for _, attribute := range attributes {
+		if value, ok := attribute.Value.label(); ok && attribute.Key != "" {
+			result[attribute.Key] = value
+		}
+	}
+	return result
+}
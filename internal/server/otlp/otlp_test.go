@@ -0,0 +1,130 @@
+// OpenTelemetry OTLP/HTTP metrics requests
+
+package otlp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const jsonRequest = `{"resourceMetrics": [{
+  "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "checkout"}}]},
+  "scopeMetrics": [{"metrics": [
+    {"name": "queue.size", "gauge": {"dataPoints": [
+      {"asInt": "3", "timeUnixNano": "1000"},
+      {"asInt": "7", "timeUnixNano": "2000"}
+    ]}},
+    {"name": "requests", "sum": {"aggregationTemporality": 2, "isMonotonic": true, "dataPoints": [
+      {"asInt": "42", "attributes": [{"key": "code", "value": {"intValue": "200"}}]}
+    ]}},
+    {"name": "errors", "sum": {"aggregationTemporality": "AGGREGATION_TEMPORALITY_DELTA", "isMonotonic": true, "dataPoints": [
+      {"asDouble": 2}
+    ]}},
+    {"name": "latency", "histogram": {"aggregationTemporality": 2, "dataPoints": [
+      {"count": "3", "sum": 0.6, "bucketCounts": ["1", "2"], "explicitBounds": [0.1]},
+      {"count": "3", "bucketCounts": ["1", "1"], "explicitBounds": [0.1], "attributes": [{"key": "code", "value": {"intValue": "500"}}]}
+    ]}},
+    {"name": "size", "histogram": {"aggregationTemporality": 1, "dataPoints": [
+      {"count": "1", "sum": 512, "bucketCounts": ["0", "1"], "explicitBounds": [100]}
+    ]}}
+  ]}]
+}]}`
+
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+// protobuf request with the monotonic cumulative sum cpu.time{host="db-1"} 12.4
+func protobufRequest() []byte {
+	var value, attribute, point, sumMessage, metric, scope, resourceMetric, request []byte
+	value = appendMessage(value, anyValueString, []byte("db-1"))
+	attribute = appendMessage(attribute, keyValueKey, []byte("host"))
+	attribute = appendMessage(attribute, keyValueValue, value)
+	point = protowire.AppendTag(point, numberPointAsDouble, protowire.Fixed64Type)
+	point = protowire.AppendFixed64(point, math.Float64bits(12.4))
+	point = appendMessage(point, numberPointAttributes, attribute)
+	sumMessage = appendMessage(sumMessage, sumDataPoints, point)
+	sumMessage = protowire.AppendTag(sumMessage, sumTemporality, protowire.VarintType)
+	sumMessage = protowire.AppendVarint(sumMessage, temporalityCumulative)
+	sumMessage = protowire.AppendTag(sumMessage, sumMonotonic, protowire.VarintType)
+	sumMessage = protowire.AppendVarint(sumMessage, 1)
+	metric = appendMessage(metric, metricName, []byte("cpu.time"))
+	metric = appendMessage(metric, metricSum, sumMessage)
+	scope = appendMessage(scope, scopeMetricsMetrics, metric)
+	resourceMetric = appendMessage(resourceMetric, resourceMetricsScopeMetrics, scope)
+	request = appendMessage(request, requestResourceMetrics, resourceMetric)
+	return request
+}
+
+func TestDecode(t *testing.T) {
+	queueSize := 7.0
+	requests := int64(42)
+	errorsDelta := int64(2)
+	latency := entity.HistogramValue{Bounds: []float64{0.1}, Counts: []uint64{1, 2}, Sum: 0.6, Count: 3}
+	size := entity.HistogramValue{Bounds: []float64{100}, Counts: []uint64{0, 1}, Sum: 512, Count: 1}
+	cpuTime := int64(12)
+
+	tests := []struct {
+		name           string
+		body           []byte
+		contentType    string
+		wantValues     []entity.Metrics
+		wantIncrements []entity.Metrics
+		err            bool
+	}{
+		{
+			name:        "positive json",
+			body:        []byte(jsonRequest),
+			contentType: ContentTypeJSON,
+			wantValues: []entity.Metrics{
+				{ID: `queue.size{service.name="checkout"}`, MType: "gauge", Value: &queueSize},
+				{ID: `requests{code="200",service.name="checkout"}`, MType: "counter", Delta: &requests},
+				{ID: `latency{service.name="checkout"}`, MType: "histogram", Histogram: &latency},
+			},
+			wantIncrements: []entity.Metrics{
+				{ID: `errors{service.name="checkout"}`, MType: "counter", Delta: &errorsDelta},
+				{ID: `size{service.name="checkout"}`, MType: "histogram", Histogram: &size},
+			},
+		},
+		{
+			name:        "positive protobuf",
+			body:        protobufRequest(),
+			contentType: ContentTypeProtobuf,
+			wantValues: []entity.Metrics{
+				{ID: `cpu.time{host="db-1"}`, MType: "counter", Delta: &cpuTime},
+			},
+		},
+		{
+			name:        "negative json",
+			body:        []byte(`{"resourceMetrics": [`),
+			contentType: ContentTypeJSON,
+			err:         true,
+		},
+		{
+			name:        "negative protobuf",
+			body:        []byte{0x0a, 0xff},
+			contentType: ContentTypeProtobuf,
+			err:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			values, increments, err := Decode(tt.body, tt.contentType)
+
+			// Assert
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantValues, values)
+			assert.Equal(t, tt.wantIncrements, increments)
+		})
+	}
+}
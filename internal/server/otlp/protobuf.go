@@ -0,0 +1,314 @@
+package otlp
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// fields of the OTLP protobuf messages
+const (
+	requestResourceMetrics = 1
+
+	resourceMetricsResource     = 1
+	resourceMetricsScopeMetrics = 2
+
+	resourceAttributes = 1
+
+	scopeMetricsMetrics = 2
+
+	metricName      = 1
+	metricGauge     = 5
+	metricSum       = 7
+	metricHistogram = 9
+
+	gaugeDataPoints = 1
+
+	sumDataPoints  = 1
+	sumTemporality = 2
+	sumMonotonic   = 3
+
+	histogramDataPoints  = 1
+	histogramTemporality = 2
+
+	numberPointTime       = 3
+	numberPointAsDouble   = 4
+	numberPointAsInt      = 6
+	numberPointAttributes = 7
+
+	histogramPointTime           = 3
+	histogramPointCount          = 4
+	histogramPointSum            = 5
+	histogramPointBucketCounts   = 6
+	histogramPointExplicitBounds = 7
+	histogramPointAttributes     = 9
+
+	keyValueKey   = 1
+	keyValueValue = 2
+
+	anyValueString = 1
+	anyValueBool   = 2
+	anyValueInt    = 3
+	anyValueDouble = 4
+)
+
+func unmarshalRequest(data []byte) (exportRequest, error) {
+	var request exportRequest
+	err := eachField(data, func(num protowire.Number, _ protowire.Type, value []byte) error {
+		if num != requestResourceMetrics {
+			return nil
+		}
+		rm, err := unmarshalResourceMetrics(value)
+		request.ResourceMetrics = append(request.ResourceMetrics, rm)
+		return err
+	})
+	return request, err
+}
+
+func unmarshalResourceMetrics(data []byte) (resourceMetrics, error) {
+	var rm resourceMetrics
+	err := eachField(data, func(num protowire.Number, _ protowire.Type, value []byte) error {
+		switch num {
+		case resourceMetricsResource:
+			return eachField(value, func(num protowire.Number, _ protowire.Type, value []byte) error {
+				if num != resourceAttributes {
+					return nil
+				}
+				kv, err := unmarshalKeyValue(value)
+				rm.Resource.Attributes = append(rm.Resource.Attributes, kv)
+				return err
+			})
+		case resourceMetricsScopeMetrics:
+			var sm scopeMetrics
+			err := eachField(value, func(num protowire.Number, _ protowire.Type, value []byte) error {
+				if num != scopeMetricsMetrics {
+					return nil
+				}
+				m, err := unmarshalMetric(value)
+				sm.Metrics = append(sm.Metrics, m)
+				return err
+			})
+			rm.ScopeMetrics = append(rm.ScopeMetrics, sm)
+			return err
+		}
+		return nil
+	})
+	return rm, err
+}
+
+func unmarshalMetric(data []byte) (metric, error) {
+	var m metric
+	err := eachField(data, func(num protowire.Number, _ protowire.Type, value []byte) error {
+		switch num {
+		case metricName:
+			m.Name = string(value)
+		case metricGauge:
+			m.Gauge = &gauge{}
+			return eachField(value, func(num protowire.Number, _ protowire.Type, value []byte) error {
+				if num != gaugeDataPoints {
+					return nil
+				}
+				point, err := unmarshalNumberDataPoint(value)
+				m.Gauge.DataPoints = append(m.Gauge.DataPoints, point)
+				return err
+			})
+		case metricSum:
+			m.Sum = &sum{}
+			return eachField(value, func(num protowire.Number, _ protowire.Type, value []byte) error {
+				switch num {
+				case sumDataPoints:
+					point, err := unmarshalNumberDataPoint(value)
+					m.Sum.DataPoints = append(m.Sum.DataPoints, point)
+					return err
+				case sumTemporality:
+					v, err := consumeVarint(value)
+					m.Sum.AggregationTemporality = temporality(v)
+					return err
+				case sumMonotonic:
+					v, err := consumeVarint(value)
+					m.Sum.IsMonotonic = v != 0
+					return err
+				}
+				return nil
+			})
+		case metricHistogram:
+			m.Histogram = &histogram{}
+			return eachField(value, func(num protowire.Number, _ protowire.Type, value []byte) error {
+				switch num {
+				case histogramDataPoints:
+					point, err := unmarshalHistogramDataPoint(value)
+					m.Histogram.DataPoints = append(m.Histogram.DataPoints, point)
+					return err
+				case histogramTemporality:
+					v, err := consumeVarint(value)
+					m.Histogram.AggregationTemporality = temporality(v)
+					return err
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return m, err
+}
+
+func unmarshalNumberDataPoint(data []byte) (numberDataPoint, error) {
+	var point numberDataPoint
+	err := eachField(data, func(num protowire.Number, _ protowire.Type, value []byte) error {
+		switch num {
+		case numberPointTime:
+			v, err := consumeFixed64(value)
+			point.TimeUnixNano = jsonUint64(v)
+			return err
+		case numberPointAsDouble:
+			v, err := consumeFixed64(value)
+			asDouble := math.Float64frombits(v)
+			point.AsDouble = &asDouble
+			return err
+		case numberPointAsInt:
+			v, err := consumeFixed64(value)
+			asInt := jsonInt64(v)
+			point.AsInt = &asInt
+			return err
+		case numberPointAttributes:
+			kv, err := unmarshalKeyValue(value)
+			point.Attributes = append(point.Attributes, kv)
+			return err
+		}
+		return nil
+	})
+	return point, err
+}
+
+func unmarshalHistogramDataPoint(data []byte) (histogramDataPoint, error) {
+	var point histogramDataPoint
+	err := eachField(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch num {
+		case histogramPointTime:
+			v, err := consumeFixed64(value)
+			point.TimeUnixNano = jsonUint64(v)
+			return err
+		case histogramPointCount:
+			v, err := consumeFixed64(value)
+			point.Count = jsonUint64(v)
+			return err
+		case histogramPointSum:
+			v, err := consumeFixed64(value)
+			pointSum := math.Float64frombits(v)
+			point.Sum = &pointSum
+			return err
+		case histogramPointBucketCounts:
+			return eachFixed64(typ, value, func(v uint64) {
+				point.BucketCounts = append(point.BucketCounts, jsonUint64(v))
+			})
+		case histogramPointExplicitBounds:
+			return eachFixed64(typ, value, func(v uint64) {
+				point.ExplicitBounds = append(point.ExplicitBounds, math.Float64frombits(v))
+			})
+		case histogramPointAttributes:
+			kv, err := unmarshalKeyValue(value)
+			point.Attributes = append(point.Attributes, kv)
+			return err
+		}
+		return nil
+	})
+	return point, err
+}
+
+func unmarshalKeyValue(data []byte) (keyValue, error) {
+	var kv keyValue
+	err := eachField(data, func(num protowire.Number, _ protowire.Type, value []byte) error {
+		switch num {
+		case keyValueKey:
+			kv.Key = string(value)
+		case keyValueValue:
+			return eachField(value, func(num protowire.Number, _ protowire.Type, value []byte) error {
+				switch num {
+				case anyValueString:
+					s := string(value)
+					kv.Value.StringValue = &s
+				case anyValueBool:
+					v, err := consumeVarint(value)
+					b := v != 0
+					kv.Value.BoolValue = &b
+					return err
+				case anyValueInt:
+					v, err := consumeVarint(value)
+					i := jsonInt64(v)
+					kv.Value.IntValue = &i
+					return err
+				case anyValueDouble:
+					v, err := consumeFixed64(value)
+					d := math.Float64frombits(v)
+					kv.Value.DoubleValue = &d
+					return err
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return kv, err
+}
+
+// eachField calls fn for every field of the message,
+// the value of length delimited fields is passed without the length
+func eachField(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		value := data[:n]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(value)
+		}
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// eachFixed64 reads a repeated fixed64 or double field, packed or not
+func eachFixed64(typ protowire.Type, data []byte, fn func(v uint64)) error {
+	if typ != protowire.BytesType {
+		v, err := consumeFixed64(data)
+		if err == nil {
+			fn(v)
+		}
+		return err
+	}
+	for len(data) > 0 {
+		v, n := protowire.ConsumeFixed64(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		fn(v)
+		data = data[n:]
+	}
+	return nil
+}
+
+func consumeVarint(data []byte) (uint64, error) {
+	v, n := protowire.ConsumeVarint(data)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	return v, nil
+}
+
+func consumeFixed64(data []byte) (uint64, error) {
+	v, n := protowire.ConsumeFixed64(data)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	return v, nil
+}
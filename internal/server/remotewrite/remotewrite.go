@@ -0,0 +1,260 @@
+// Prometheus remote_write requests
+package remotewrite
+
+import (
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// fields of the remote_write protobuf messages
+const (
+	writeRequestTimeseries = 1
+	writeRequestMetadata   = 3
+
+	timeSeriesLabels  = 1
+	timeSeriesSamples = 2
+
+	labelName  = 1
+	labelValue = 2
+
+	sampleValue     = 1
+	sampleTimestamp = 2
+
+	metadataType       = 1
+	metadataFamilyName = 2
+
+	// MetricMetadata.MetricType of counters
+	metadataCounter = 1
+)
+
+const (
+	nameLabel     = "__name__"
+	counterSuffix = "_total"
+)
+
+type sample struct {
+	value     float64
+	timestamp int64
+}
+
+type series struct {
+	name   string
+	labels map[string]string
+	latest *sample
+}
+
+// Receiver decodes requests and keeps the metadata between them,
+// Prometheus sends metadata in separate periodic requests
+type Receiver struct {
+	mu sync.RWMutex
+	// names of metric families with the counter metadata
+	counters map[string]bool
+}
+
+func New() *Receiver {
+	return &Receiver{
+		counters: make(map[string]bool),
+	}
+}
+
+// Decode converts a snappy compressed WriteRequest to metrics,
+// the latest sample of every series is kept, also when the series is repeated. Series whose names end with _total
+// or have the counter metadata of this or an earlier request become counters with the value rounded,
+// samples that are not finite (including staleness markers) are skipped
+func (r *Receiver) Decode(body []byte) ([]entity.Metrics, error) {
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var allSeries []series
+	metadata := make(map[string]bool)
+	err = eachField(data, func(num protowire.Number, value []byte) error {
+		switch num {
+		case writeRequestTimeseries:
+			s, err := parseSeries(value)
+			if err != nil {
+				return err
+			}
+			allSeries = append(allSeries, s)
+		case writeRequestMetadata:
+			name, isCounter, err := parseMetadata(value)
+			if err != nil {
+				return err
+			}
+			metadata[name] = isCounter
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.updateCounters(metadata)
+
+	// a series can be repeated in several entries of one request
+	var ids []string
+	latest := make(map[string]series)
+	for _, s := range allSeries {
+		if s.name == "" || s.latest == nil {
+			continue
+		}
+		id := entity.SeriesID(s.name, s.labels)
+		if current, ok := latest[id]; ok {
+			if s.latest.timestamp >= current.latest.timestamp {
+				latest[id] = s
+			}
+			continue
+		}
+		ids = append(ids, id)
+		latest[id] = s
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	metrics := make([]entity.Metrics, 0, len(ids))
+	for _, id := range ids {
+		s := latest[id]
+		value := s.latest.value
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+
+		if r.counters[s.name] || strings.HasSuffix(s.name, counterSuffix) {
+			delta := int64(math.Round(value))
+			metrics = append(metrics, entity.Metrics{ID: id, MType: entity.CounterMetric, Delta: &delta})
+			continue
+		}
+		metrics = append(metrics, entity.Metrics{ID: id, MType: entity.GaugeMetric, Value: &value})
+	}
+
+	return metrics, nil
+}
+
+// updateCounters saves the metadata of the request, a family may change its type
+func (r *Receiver) updateCounters(metadata map[string]bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, isCounter := range metadata {
+		if isCounter {
+			r.counters[name] = true
+		} else {
+			delete(r.counters, name)
+		}
+	}
+}
+
+func parseSeries(data []byte) (series, error) {
+	s := series{labels: make(map[string]string)}
+	err := eachField(data, func(num protowire.Number, value []byte) error {
+		switch num {
+		case timeSeriesLabels:
+			name, labelVal, err := parseLabel(value)
+			if err != nil {
+				return err
+			}
+			if name == nameLabel {
+				s.name = labelVal
+			} else {
+				s.labels[name] = labelVal
+			}
+		case timeSeriesSamples:
+			smp, err := parseSample(value)
+			if err != nil {
+				return err
+			}
+			if s.latest == nil || smp.timestamp >= s.latest.timestamp {
+				s.latest = &smp
+			}
+		}
+		return nil
+	})
+	return s, err
+}
+
+func parseLabel(data []byte) (string, string, error) {
+	var name, value string
+	err := eachField(data, func(num protowire.Number, field []byte) error {
+		switch num {
+		case labelName:
+			name = string(field)
+		case labelValue:
+			value = string(field)
+		}
+		return nil
+	})
+	return name, value, err
+}
+
+func parseSample(data []byte) (sample, error) {
+	var smp sample
+	err := eachField(data, func(num protowire.Number, field []byte) error {
+		switch num {
+		case sampleValue:
+			v, n := protowire.ConsumeFixed64(field)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			smp.value = math.Float64frombits(v)
+		case sampleTimestamp:
+			v, n := protowire.ConsumeVarint(field)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			smp.timestamp = int64(v)
+		}
+		return nil
+	})
+	return smp, err
+}
+
+func parseMetadata(data []byte) (string, bool, error) {
+	var name string
+	var isCounter bool
+	err := eachField(data, func(num protowire.Number, field []byte) error {
+		switch num {
+		case metadataType:
+			v, n := protowire.ConsumeVarint(field)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			isCounter = v == metadataCounter
+		case metadataFamilyName:
+			name = string(field)
+		}
+		return nil
+	})
+	return name, isCounter, err
+}
+
+// eachField calls fn for every field of the message,
+// the value of length delimited fields is passed without the length
+func eachField(data []byte, fn func(num protowire.Number, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		value := data[:n]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(value)
+		}
+		if err := fn(num, value); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Prometheus remote_write requests
+
+package remotewrite
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type testSample struct {
+	value     float64
+	timestamp int64
+}
+
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func encodeSeries(labels [][2]string, samples []testSample) []byte {
+	var s []byte
+	for _, label := range labels {
+		var l []byte
+		l = appendMessage(l, labelName, []byte(label[0]))
+		l = appendMessage(l, labelValue, []byte(label[1]))
+		s = appendMessage(s, timeSeriesLabels, l)
+	}
+	for _, smp := range samples {
+		var v []byte
+		v = protowire.AppendTag(v, sampleValue, protowire.Fixed64Type)
+		v = protowire.AppendFixed64(v, math.Float64bits(smp.value))
+		v = protowire.AppendTag(v, sampleTimestamp, protowire.VarintType)
+		v = protowire.AppendVarint(v, uint64(smp.timestamp))
+		s = appendMessage(s, timeSeriesSamples, v)
+	}
+	return s
+}
+
+func encodeMetadata(name string, metricType uint64) []byte {
+	var m []byte
+	m = protowire.AppendTag(m, metadataType, protowire.VarintType)
+	m = protowire.AppendVarint(m, metricType)
+	m = appendMessage(m, metadataFamilyName, []byte(name))
+	return m
+}
+
+func TestDecode(t *testing.T) {
+	var request []byte
+	request = appendMessage(request, writeRequestTimeseries, encodeSeries(
+		[][2]string{{"__name__", "node_load1"}, {"instance", "db-1"}, {"job", "node"}},
+		[]testSample{{value: 0.5, timestamp: 2000}, {value: 0.25, timestamp: 1000}},
+	))
+	request = appendMessage(request, writeRequestTimeseries, encodeSeries(
+		[][2]string{{"__name__", "http_requests_total"}},
+		[]testSample{{value: 41.6, timestamp: 1000}},
+	))
+	request = appendMessage(request, writeRequestTimeseries, encodeSeries(
+		[][2]string{{"__name__", "process_cpu_seconds"}},
+		[]testSample{{value: 12, timestamp: 1000}},
+	))
+	request = appendMessage(request, writeRequestTimeseries, encodeSeries(
+		[][2]string{{"__name__", "stale"}},
+		[]testSample{{value: math.NaN(), timestamp: 1000}},
+	))
+	request = appendMessage(request, writeRequestMetadata, encodeMetadata("process_cpu_seconds", metadataCounter))
+
+	var repeated []byte
+	repeated = appendMessage(repeated, writeRequestTimeseries, encodeSeries(
+		[][2]string{{"__name__", "node_load1"}, {"instance", "db-1"}},
+		[]testSample{{value: 0.75, timestamp: 3000}},
+	))
+	repeated = appendMessage(repeated, writeRequestTimeseries, encodeSeries(
+		[][2]string{{"instance", "db-1"}, {"__name__", "node_load1"}},
+		[]testSample{{value: 0.1, timestamp: 1000}},
+	))
+	repeated = appendMessage(repeated, writeRequestTimeseries, encodeSeries(
+		[][2]string{{"__name__", "node_load1"}, {"instance", "db-2"}},
+		[]testSample{{value: 0.2, timestamp: 1000}},
+	))
+
+	load := 0.5
+	requests := int64(42)
+	cpu := int64(12)
+	loadLatest := 0.75
+	loadOther := 0.2
+
+	tests := []struct {
+		name string
+		body []byte
+		want []entity.Metrics
+		err  bool
+	}{
+		{
+			name: "positive",
+			body: snappy.Encode(nil, request),
+			want: []entity.Metrics{
+				{ID: `node_load1{instance="db-1",job="node"}`, MType: "gauge", Value: &load},
+				{ID: "http_requests_total", MType: "counter", Delta: &requests},
+				{ID: "process_cpu_seconds", MType: "counter", Delta: &cpu},
+			},
+		},
+		{
+			name: "positive repeated series",
+			body: snappy.Encode(nil, repeated),
+			want: []entity.Metrics{
+				{ID: `node_load1{instance="db-1"}`, MType: "gauge", Value: &loadLatest},
+				{ID: `node_load1{instance="db-2"}`, MType: "gauge", Value: &loadOther},
+			},
+		},
+		{
+			name: "negative snappy",
+			body: request,
+			err:  true,
+		},
+		{
+			name: "negative protobuf",
+			body: snappy.Encode(nil, []byte{0x0a, 0xff}),
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := New().Decode(tt.body)
+
+			// Assert
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// MetricMetadata.MetricType of gauges
+const metadataGauge = 2
+
+func TestReceiver_Decode_metadata(t *testing.T) {
+	// Arrange
+	var metadata []byte
+	metadata = appendMessage(metadata, writeRequestMetadata, encodeMetadata("node_cpu_seconds", metadataCounter))
+	var samples []byte
+	samples = appendMessage(samples, writeRequestTimeseries, encodeSeries(
+		[][2]string{{"__name__", "node_cpu_seconds"}, {"mode", "idle"}},
+		[]testSample{{value: 120, timestamp: 1000}},
+	))
+	var gaugeMetadata []byte
+	gaugeMetadata = appendMessage(gaugeMetadata, writeRequestMetadata, encodeMetadata("node_cpu_seconds", metadataGauge))
+
+	cpu := int64(120)
+	cpuGauge := 120.0
+	receiver := New()
+
+	// Act
+	before, beforeErr := receiver.Decode(snappy.Encode(nil, samples))
+	_, metadataErr := receiver.Decode(snappy.Encode(nil, metadata))
+	after, afterErr := receiver.Decode(snappy.Encode(nil, samples))
+	_, gaugeErr := receiver.Decode(snappy.Encode(nil, gaugeMetadata))
+	changed, changedErr := receiver.Decode(snappy.Encode(nil, samples))
+
+	// Assert
+	assert.NoError(t, errors.Join(beforeErr, metadataErr, afterErr, gaugeErr, changedErr))
+	assert.Equal(t, []entity.Metrics{{ID: `node_cpu_seconds{mode="idle"}`, MType: entity.GaugeMetric, Value: &cpuGauge}}, before)
+	assert.Equal(t, []entity.Metrics{{ID: `node_cpu_seconds{mode="idle"}`, MType: entity.CounterMetric, Delta: &cpu}}, after)
+	assert.Equal(t, []entity.Metrics{{ID: `node_cpu_seconds{mode="idle"}`, MType: entity.GaugeMetric, Value: &cpuGauge}}, changed)
+}
@@ -0,0 +1,326 @@
+// Scraping of agents in the pull mode
+package scrape
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/korovindenis/go-pc-metrics/internal/encrypt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+//go:generate mockery --name usecase --exported
+type usecase interface {
+	SaveAllDataBatchUsecase(ctx context.Context, metrics []entity.Metrics) error
+	SaveAgentUsecase(ctx context.Context, agent entity.Agent) error
+}
+
+//go:generate mockery --name cfg --exported
+type cfg interface {
+	GetScrapeTargets() []string
+	GetScrapeInterval() time.Duration
+	GetScrapeTimeout() time.Duration
+	GetKey() string
+	UseCryptoKey() bool
+}
+
+//go:generate mockery --name log --exported
+type log interface {
+	Info(msg string, fields ...zapcore.Field)
+	Error(msg string, fields ...zapcore.Field)
+}
+
+type Manager struct {
+	usecase      usecase
+	log          log
+	client       *http.Client
+	interval     time.Duration
+	timeout      time.Duration
+	secretKey    string
+	useCryptoKey bool
+	// targets from the flags
+	static []entity.ScrapeTarget
+
+	mu       sync.RWMutex
+	scrapers map[string]*scraper
+}
+
+// running scrape loop of one target
+type scraper struct {
+	cancel context.CancelFunc
+	target entity.ScrapeTarget
+}
+
+func New(u usecase, config cfg, log log) (*Manager, error) {
+	log.Info("Scrape manager is enabled")
+
+	static := make([]entity.ScrapeTarget, 0, len(config.GetScrapeTargets()))
+	for _, address := range config.GetScrapeTargets() {
+		static = append(static, entity.ScrapeTarget{Address: strings.TrimSpace(address)})
+	}
+
+	return &Manager{
+		usecase:      u,
+		log:          log,
+		client:       &http.Client{},
+		interval:     config.GetScrapeInterval(),
+		timeout:      config.GetScrapeTimeout(),
+		secretKey:    config.GetKey(),
+		useCryptoKey: config.UseCryptoKey(),
+		static:       static,
+		scrapers:     make(map[string]*scraper),
+	}, nil
+}
+
+// Run scrapes the static targets and the discovered ones,
+// every list from the discovery replaces the previous one
+func (m *Manager) Run(ctx context.Context, discovered <-chan []entity.ScrapeTarget) {
+	m.reconcile(ctx, nil)
+	for {
+		select {
+		case <-ctx.Done():
+			m.reconcile(ctx, nil)
+			return
+		case targets := <-discovered:
+			m.reconcile(ctx, targets)
+		}
+	}
+}
+
+// GetTargets returns targets with their health sorted by address
+func (m *Manager) GetTargets() []entity.ScrapeTarget {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	targets := make([]entity.ScrapeTarget, 0, len(m.scrapers))
+	for _, s := range m.scrapers {
+		targets = append(targets, s.target)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Address < targets[j].Address
+	})
+	return targets
+}
+
+// reconcile starts scrapers of new targets and stops scrapers of removed ones,
+// labels of running scrapers are updated, a cancelled ctx stops all
+func (m *Manager) reconcile(ctx context.Context, discovered []entity.ScrapeTarget) {
+	wanted := make(map[string]entity.ScrapeTarget)
+	if ctx.Err() == nil {
+		for _, target := range append(discovered, m.static...) {
+			if target.Address != "" {
+				wanted[target.Address] = target
+			}
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for address, s := range m.scrapers {
+		if _, ok := wanted[address]; !ok {
+			m.log.Info("stop scraping " + address)
+			s.cancel()
+			delete(m.scrapers, address)
+		}
+	}
+	for address, target := range wanted {
+		if s, ok := m.scrapers[address]; ok {
+			s.target.Labels = target.Labels
+			continue
+		}
+
+		m.log.Info("start scraping " + address)
+		scrapeCtx, cancel := context.WithCancel(ctx)
+		target.Health = entity.TargetUnknown
+		m.scrapers[address] = &scraper{cancel: cancel, target: target}
+		go m.loop(scrapeCtx, address)
+	}
+}
+
+// loop scrapes the target at startup and then on every interval
+func (m *Manager) loop(ctx context.Context, address string) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.scrapeTarget(ctx, address)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scrapeTarget saves metrics of the agent and the health of the target:
+// gauges scrape.<target>.up, scrape.<target>.duration_seconds and scrape.<target>.samples.
+// Labels of the target are added to all series, so the same metrics of several agents don't overwrite each other
+func (m *Manager) scrapeTarget(ctx context.Context, address string) {
+	labels := m.targetLabels(address)
+	start := time.Now()
+	metrics, header, err := m.fetch(ctx, address)
+	if err == nil {
+		for i := range metrics {
+			metrics[i].ID = withLabels(metrics[i].ID, labels)
+		}
+		err = m.usecase.SaveAllDataBatchUsecase(ctx, metrics)
+	}
+	duration := time.Since(start).Seconds()
+
+	target, ok := m.updateHealth(address, start, duration, err)
+	if !ok {
+		// removed while it was scraped
+		return
+	}
+
+	up := 1.0
+	if err != nil {
+		m.log.Error("scrape "+address, zap.Error(err))
+		up = 0
+		metrics = nil
+	} else if agentID := header.Get(entity.AgentIDHeader); agentID != "" {
+		group := header.Get(entity.AgentGroupHeader)
+		if group == "" {
+			group = target.Labels["group"]
+		}
+		err := m.usecase.SaveAgentUsecase(ctx, entity.Agent{
+			ID:             agentID,
+			Group:          group,
+			Address:        address,
+			Version:        header.Get(entity.AgentVersionHeader),
+			ReportInterval: int(m.interval.Seconds()),
+			Metrics:        int64(len(metrics)),
+		})
+		if err != nil {
+			m.log.Error("register "+address, zap.Error(err))
+		}
+	}
+
+	samples := float64(len(metrics))
+	health := []entity.Metrics{
+		{ID: withLabels("scrape."+address+".up", labels), MType: entity.GaugeMetric, Value: &up},
+		{ID: withLabels("scrape."+address+".duration_seconds", labels), MType: entity.GaugeMetric, Value: &duration},
+		{ID: withLabels("scrape."+address+".samples", labels), MType: entity.GaugeMetric, Value: &samples},
+	}
+	if err := m.usecase.SaveAllDataBatchUsecase(ctx, health); err != nil {
+		m.log.Error("save scrape health "+address, zap.Error(err))
+	}
+}
+
+func (m *Manager) targetLabels(address string) map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if s, ok := m.scrapers[address]; ok {
+		return s.target.Labels
+	}
+	return nil
+}
+
+// withLabels adds the labels of the target to the series ID,
+// they replace labels of the series with the same name
+func withLabels(id string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return id
+	}
+
+	name, seriesLabels := entity.ParseSeriesID(id)
+	merged := make(map[string]string, len(seriesLabels)+len(labels))
+	for key, value := range seriesLabels {
+		merged[key] = value
+	}
+	for key, value := range labels {
+		merged[key] = value
+	}
+	return entity.SeriesID(name, merged)
+}
+
+func (m *Manager) updateHealth(address string, start time.Time, duration float64, err error) (entity.ScrapeTarget, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.scrapers[address]
+	if !ok {
+		return entity.ScrapeTarget{}, false
+	}
+	s.target.LastScrape = start
+	s.target.LastDuration = duration
+	s.target.Health = entity.TargetUp
+	s.target.LastError = ""
+	if err != nil {
+		s.target.Health = entity.TargetDown
+		s.target.LastError = err.Error()
+	}
+	return s.target, true
+}
+
+func (m *Manager) fetch(ctx context.Context, target string) ([]entity.Metrics, http.Header, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL(target), http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("status %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if m.secretKey != "" {
+		if m.useCryptoKey {
+			decryptBody, err := encrypt.Decrypt(m.secretKey, string(body))
+			if err != nil {
+				return nil, nil, err
+			}
+			body = []byte(decryptBody)
+		} else if !checkHMAC(body, m.secretKey, resp.Header.Get("HashSHA256")) {
+			return nil, nil, entity.ErrSignMismatch
+		}
+	}
+
+	var metrics []entity.Metrics
+	if err := json.Unmarshal(body, &metrics); err != nil {
+		return nil, nil, err
+	}
+
+	return metrics, resp.Header, nil
+}
+
+// targetURL adds the scheme and the path to the host:port target
+func targetURL(target string) string {
+	if strings.Contains(target, "://") {
+		return target
+	}
+	return "http://" + target + entity.ScrapePath
+}
+
+func checkHMAC(body []byte, key, sign string) bool {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write(body)
+	expected := hex.EncodeToString(h.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(sign))
+}
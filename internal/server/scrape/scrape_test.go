@@ -0,0 +1,245 @@
+// Scraping of agents in the pull mode
+
+package scrape
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/korovindenis/go-pc-metrics/internal/domain/entity"
+	"github.com/korovindenis/go-pc-metrics/internal/server/scrape/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	secretKey = "secret"
+	pulled    = `[{"id":"Alloc","type":"gauge","value":1024},{"id":"PollCount","type":"counter","delta":5}]`
+)
+
+func sign(body string) string {
+	h := hmac.New(sha256.New, []byte(secretKey))
+	h.Write([]byte(body))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestManager_scrapeTarget(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/scrape/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("HashSHA256", sign(pulled))
+		w.Header().Set(entity.AgentIDHeader, "c0ffee")
+		w.Header().Set(entity.AgentVersionHeader, "1.0")
+		w.Write([]byte(pulled))
+	})
+	mux.HandleFunc("/forged/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("HashSHA256", sign("[]"))
+		w.Write([]byte(pulled))
+	})
+	mux.HandleFunc("/slow/", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.Header().Set("HashSHA256", sign(pulled))
+		w.Write([]byte(pulled))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name     string
+		target   string
+		up       float64
+		samples  float64
+		register bool
+		health   string
+	}{
+		{
+			name:     "positive",
+			target:   host,
+			up:       1,
+			samples:  2,
+			register: true,
+			health:   entity.TargetUp,
+		},
+		{
+			name:   "sign mismatch",
+			target: server.URL + "/forged/",
+			health: entity.TargetDown,
+		},
+		{
+			name:   "timeout",
+			target: server.URL + "/slow/",
+			health: entity.TargetDown,
+		},
+		{
+			name:   "not found",
+			target: server.URL + "/missing/",
+			health: entity.TargetDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var mu sync.Mutex
+			saved := make(entity.GaugeType)
+			u := mocks.NewUsecase(t)
+			u.On("SaveAllDataBatchUsecase", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, metric := range args.Get(1).([]entity.Metrics) {
+					if metric.Value != nil {
+						saved[metric.ID] = *metric.Value
+					}
+				}
+			}).Return(nil)
+			if tt.register {
+				u.On("SaveAgentUsecase", mock.Anything, entity.Agent{
+					ID:             "c0ffee",
+					Group:          "web",
+					Address:        tt.target,
+					Version:        "1.0",
+					ReportInterval: 10,
+					Metrics:        2,
+				}).Return(nil).Once()
+			}
+
+			cfg := mocks.NewCfg(t)
+			cfg.On("GetScrapeTargets").Return([]string{tt.target})
+			cfg.On("GetScrapeInterval").Return(10 * time.Second)
+			cfg.On("GetScrapeTimeout").Return(100 * time.Millisecond)
+			cfg.On("GetKey").Return(secretKey)
+			cfg.On("UseCryptoKey").Return(false)
+			log := mocks.NewLog(t)
+			log.On("Info", mock.Anything).Return("")
+			log.On("Error", mock.Anything, mock.Anything).Return("").Maybe()
+			manager, _ := New(u, cfg, log)
+
+			// Act
+			manager.scrapers[tt.target] = &scraper{
+				cancel: func() {},
+				target: entity.ScrapeTarget{Address: tt.target, Labels: map[string]string{"group": "web"}},
+			}
+			manager.scrapeTarget(context.Background(), tt.target)
+
+			// Assert
+			prefix := "scrape." + tt.target
+			assert.Equal(t, tt.up, saved[prefix+`.up{group="web"}`])
+			assert.Equal(t, tt.samples, saved[prefix+`.samples{group="web"}`])
+			assert.Contains(t, saved, prefix+`.duration_seconds{group="web"}`)
+			if tt.up == 1 {
+				assert.Equal(t, 1024.0, saved[`Alloc{group="web"}`])
+			}
+			targets := manager.GetTargets()
+			assert.Len(t, targets, 1)
+			assert.Equal(t, tt.health, targets[0].Health)
+			assert.Equal(t, tt.health == entity.TargetDown, targets[0].LastError != "")
+		})
+	}
+}
+
+func TestManager_reconcile(t *testing.T) {
+	// Arrange
+	u := mocks.NewUsecase(t)
+	u.On("SaveAllDataBatchUsecase", mock.Anything, mock.Anything).Return(nil).Maybe()
+	cfg := mocks.NewCfg(t)
+	cfg.On("GetScrapeTargets").Return([]string{"static:8081"})
+	cfg.On("GetScrapeInterval").Return(time.Hour)
+	cfg.On("GetScrapeTimeout").Return(10 * time.Millisecond)
+	cfg.On("GetKey").Return("")
+	cfg.On("UseCryptoKey").Return(false)
+	log := mocks.NewLog(t)
+	log.On("Info", mock.Anything).Return("")
+	log.On("Error", mock.Anything, mock.Anything).Return("").Maybe()
+	manager, _ := New(u, cfg, log)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act
+	manager.reconcile(ctx, []entity.ScrapeTarget{
+		{Address: "a:8081", Labels: map[string]string{"group": "web"}},
+		{Address: "b:8081"},
+	})
+	manager.reconcile(ctx, []entity.ScrapeTarget{
+		{Address: "a:8081", Labels: map[string]string{"group": "db"}},
+	})
+	got := manager.GetTargets()
+	cancel()
+	manager.reconcile(ctx, nil)
+
+	// Assert
+	assert.Len(t, got, 2)
+	assert.Equal(t, "a:8081", got[0].Address)
+	assert.Equal(t, "db", got[0].Labels["group"])
+	assert.Equal(t, "static:8081", got[1].Address)
+	assert.Empty(t, manager.GetTargets())
+}
+
+func Test_withLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		labels map[string]string
+		want   string
+	}{
+		{
+			name: "without target labels",
+			id:   "Alloc",
+			want: "Alloc",
+		},
+		{
+			name:   "target labels",
+			id:     "Alloc",
+			labels: map[string]string{"group": "web", "env": "prod"},
+			want:   `Alloc{env="prod",group="web"}`,
+		},
+		{
+			name:   "labels of the series",
+			id:     `disk_used{group="agent",path="/"}`,
+			labels: map[string]string{"group": "web"},
+			want:   `disk_used{group="web",path="/"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := withLabels(tt.id, tt.labels)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_targetURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{
+			name:   "host and port",
+			target: "10.0.0.5:8081",
+			want:   "http://10.0.0.5:8081/scrape/",
+		},
+		{
+			name:   "url",
+			target: "https://agent.example.com/scrape/",
+			want:   "https://agent.example.com/scrape/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := targetURL(tt.target)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}